@@ -0,0 +1,67 @@
+package main
+
+import (
+	"dev/cqb13/meteor-addon-scanner/internal"
+	"flag"
+	"log/slog"
+)
+
+func authorsCommand(args []string) int {
+	flags := flag.NewFlagSet("authors", flag.ExitOnError)
+	configPath := flags.String("config", "", "config file, used for output.pretty, optional")
+	inputPath := flags.String("input", "", "addons written by a previous scan")
+	outputPath := flags.String("output", "", "file the author index is written to, - for stdout")
+	overwrite := flags.Bool("overwrite", false, "replace the output file if it exists")
+	contributors := flags.Bool("contributors", false, "match authors against the contributors of their repos, requires a GitHub API key")
+	if err := parseFlags(flags, args); err != nil {
+		return exit(err)
+	}
+
+	return exit(runAuthors(*configPath, *inputPath, *outputPath, *overwrite, *contributors))
+}
+
+func runAuthors(configPath string, inputPath string, outputPath string, overwrite bool, contributors bool) error {
+	if inputPath == "" || outputPath == "" {
+		return configError("No input or output file provided: authors -input addons.json -output authors.json")
+	}
+
+	if err := validateOutputPath(outputPath, overwrite); err != nil {
+		return configError("%v", err)
+	}
+
+	pretty, err := loadPretty(configPath)
+	if err != nil {
+		return err
+	}
+
+	output, err := internal.LoadOutput(inputPath)
+	if err != nil {
+		return configError("%v", err)
+	}
+
+	if contributors {
+		if err := authenticate(); err != nil {
+			return err
+		}
+	}
+
+	return writeAuthorIndex(outputPath, output, pretty, contributors)
+}
+
+func writeAuthorIndex(path string, output *internal.Output, pretty bool, contributors bool) error {
+	index := internal.BuildAuthorIndex(output.Addons, contributors, output.GeneratedAt)
+	if err := internal.SaveAuthorIndex(path, index, pretty); err != nil {
+		return outputError("%v", err)
+	}
+
+	resolved := 0
+	for _, author := range index.Authors {
+		if author.Login != "" {
+			resolved++
+		}
+	}
+
+	slog.Info("Wrote author index", "output", path, "authors", len(index.Authors), "resolved", resolved)
+
+	return nil
+}
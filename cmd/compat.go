@@ -0,0 +1,53 @@
+package main
+
+import (
+	"dev/cqb13/meteor-addon-scanner/internal"
+	"flag"
+	"log/slog"
+)
+
+func compatCommand(args []string) int {
+	flags := flag.NewFlagSet("compat", flag.ExitOnError)
+	configPath := flags.String("config", "", "config file, used for output.pretty, optional")
+	inputPath := flags.String("input", "", "addons written by a previous scan")
+	outputPath := flags.String("output", "", "file the compatibility matrix is written to, - for stdout")
+	overwrite := flags.Bool("overwrite", false, "replace the output file if it exists")
+	if err := parseFlags(flags, args); err != nil {
+		return exit(err)
+	}
+
+	return exit(runCompat(*configPath, *inputPath, *outputPath, *overwrite))
+}
+
+func runCompat(configPath string, inputPath string, outputPath string, overwrite bool) error {
+	if inputPath == "" || outputPath == "" {
+		return configError("No input or output file provided: compat -input addons.json -output compat.json")
+	}
+
+	if err := validateOutputPath(outputPath, overwrite); err != nil {
+		return configError("%v", err)
+	}
+
+	pretty, err := loadPretty(configPath)
+	if err != nil {
+		return err
+	}
+
+	output, err := internal.LoadOutput(inputPath)
+	if err != nil {
+		return configError("%v", err)
+	}
+
+	return writeCompatibility(outputPath, output, pretty)
+}
+
+func writeCompatibility(path string, output *internal.Output, pretty bool) error {
+	compatibility := internal.BuildCompatibility(output.Addons, output.GeneratedAt)
+	if err := internal.SaveCompatibility(path, compatibility, pretty); err != nil {
+		return outputError("%v", err)
+	}
+
+	slog.Info("Wrote compatibility matrix", "output", path, "versions", len(compatibility.Versions))
+
+	return nil
+}
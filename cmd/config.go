@@ -0,0 +1,44 @@
+package main
+
+import (
+	"dev/cqb13/meteor-addon-scanner/internal"
+	"flag"
+	"fmt"
+)
+
+func configCommand(args []string) int {
+	if len(args) == 0 || args[0] != "check" {
+		fmt.Println("Usage: scanner config check -config config.json")
+		return exitConfig
+	}
+
+	flags := flag.NewFlagSet("config check", flag.ExitOnError)
+	configPath := flags.String("config", "config.json", "config file")
+	if err := parseFlags(flags, args[1:]); err != nil {
+		return exit(err)
+	}
+
+	return exit(runConfigCheck(*configPath))
+}
+
+func runConfigCheck(configPath string) error {
+	if err := internal.ValidateConfigPath(configPath); err != nil {
+		return configError("%v", err)
+	}
+
+	problems, err := internal.CheckConfig(configPath)
+	if err != nil {
+		return configError("%v", err)
+	}
+
+	if len(problems) == 0 {
+		fmt.Printf("%s is valid\n", configPath)
+		return nil
+	}
+
+	for _, problem := range problems {
+		fmt.Printf("\t%s\n", problem)
+	}
+
+	return configError("Found %d problems in %s", len(problems), configPath)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"dev/cqb13/meteor-addon-scanner/internal"
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"flag"
+	"log/slog"
+	"time"
+)
+
+func diffCommand(args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	configPath := flags.String("config", "", "config file, used to tell if removed addons were blacklisted, optional")
+	retryLogPath := flags.String("retry-log", "", "retry log of the new scan, used to tell why removed addons failed, optional")
+	outputPath := flags.String("output", "-", "file the changes are written to, - for stdout")
+	overwrite := flags.Bool("overwrite", false, "replace the output file if it exists")
+	feedsDir := flags.String("feeds", "", "directory the Atom feeds of new addons, releases and verified addons are updated in, requires -config, optional")
+	historyPath := flags.String("history", "", "history store, used to tell when added addons were first seen for their feed entries, optional")
+	if err := parseFlags(flags, args); err != nil {
+		return exit(err)
+	}
+
+	if flags.NArg() != 2 {
+		return exit(configError("Provide the old and new addons: diff old.json new.json"))
+	}
+
+	return exit(runDiff(flags.Arg(0), flags.Arg(1), *configPath, *retryLogPath, *outputPath, *overwrite, *feedsDir, *historyPath))
+}
+
+func runDiff(oldPath string, newPath string, configPath string, retryLogPath string, outputPath string, overwrite bool, feedsDir string, historyPath string) error {
+	if err := validateOutputPath(outputPath, overwrite); err != nil {
+		return configError("%v", err)
+	}
+
+	if feedsDir != "" && configPath == "" {
+		return configError("Updating the feeds requires a config")
+	}
+
+	var config *scanner.Config
+	if configPath != "" {
+		var err error
+		config, err = loadConfig(configPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	var retryLog *scanner.RetryLog
+	if retryLogPath != "" {
+		var err error
+		retryLog, err = internal.LoadRetryLog(retryLogPath)
+		if err != nil {
+			return configError("%v", err)
+		}
+	}
+
+	var history internal.History
+	if historyPath != "" {
+		var err error
+		history, err = internal.LoadHistory(historyPath)
+		if err != nil {
+			return configError("%v", err)
+		}
+	}
+
+	oldAddons, err := internal.LoadAddons(oldPath)
+	if err != nil {
+		return configError("%v", err)
+	}
+
+	newAddons, err := internal.LoadAddons(newPath)
+	if err != nil {
+		return configError("%v", err)
+	}
+
+	changes := internal.DiffAddons(oldAddons, newAddons, config, retryLog, time.Now())
+	logChanges(changes)
+
+	if err := internal.SaveChanges(outputPath, changes); err != nil {
+		return outputError("%v", err)
+	}
+
+	if feedsDir != "" {
+		if err := internal.UpdateFeeds(feedsDir, changes, newAddons, history, config, time.Now()); err != nil {
+			return outputError("%v", err)
+		}
+		slog.Info("Updated feeds", "stage", "feeds", "output", feedsDir)
+	}
+
+	return nil
+}
+
+func logChanges(changes *internal.Changes) {
+	slog.Info("Found changes", "stage", "diff",
+		"added", len(changes.Added),
+		"removed", len(changes.Removed),
+		"verification", len(changes.Verification),
+		"mc_versions", len(changes.McVersions),
+		"releases", len(changes.Releases),
+		"features", len(changes.Features))
+}
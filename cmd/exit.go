@@ -0,0 +1,135 @@
+package main
+
+import (
+	"dev/cqb13/meteor-addon-scanner/internal"
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// exit codes of the scanner, anything but exitOk and exitPartial means the output must not be deployed
+const (
+	exitOk        = 0
+	exitFailure   = 1
+	exitConfig    = 2
+	exitAuth      = 3
+	exitDiscovery = 4
+	exitOutput    = 5
+	exitPartial   = 6
+)
+
+// exitError is an error that ends the run with a specific exit code
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+func configError(format string, a ...any) error {
+	return &exitError{exitConfig, fmt.Errorf(format, a...)}
+}
+
+func discoveryError(err error) error {
+	return &exitError{exitDiscovery, err}
+}
+
+func outputError(format string, a ...any) error {
+	return &exitError{exitOutput, fmt.Errorf(format, a...)}
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return exitOk
+	}
+
+	// a rejected key is an auth error no matter which stage noticed it
+	if errors.Is(err, scanner.ErrUnauthorized) {
+		return exitAuth
+	}
+
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+
+	return exitFailure
+}
+
+// RunSummary is the machine readable result of a run
+type RunSummary struct {
+	Status            string    `json:"status"`
+	ExitCode          int       `json:"exit_code"`
+	Error             string    `json:"error,omitempty"`
+	StartedAt         time.Time `json:"started_at"`
+	FinishedAt        time.Time `json:"finished_at"`
+	DurationSeconds   float64   `json:"duration_seconds"`
+	ReposLocated      int       `json:"repos_located"`
+	ValidAddons       int       `json:"valid_addons"`
+	InvalidRepos      int       `json:"invalid_repos"`
+	ArchivedAddons    int       `json:"archived_addons"`
+	StaleAddons       int       `json:"stale_addons"`
+	TransientFailures []string  `json:"transient_failures"`
+	Warnings          []string  `json:"warnings"`
+}
+
+func newRunSummary(startTime time.Time) *RunSummary {
+	return &RunSummary{
+		StartedAt:         startTime,
+		TransientFailures: []string{},
+		Warnings:          []string{},
+	}
+}
+
+// warn prints a problem that does not stop the run, but makes it partial
+func (s *RunSummary) warn(format string, a ...any) {
+	message := fmt.Sprintf(format, a...)
+	slog.Warn(message)
+	s.Warnings = append(s.Warnings, message)
+}
+
+// finish sets the status of the run from err and returns the exit code
+func (s *RunSummary) finish(err error) int {
+	code := exitCode(err)
+	if code == exitOk && (len(s.TransientFailures) != 0 || len(s.Warnings) != 0) {
+		code = exitPartial
+	}
+
+	s.ExitCode = code
+	s.FinishedAt = time.Now()
+	s.DurationSeconds = s.FinishedAt.Sub(s.StartedAt).Seconds()
+
+	switch code {
+	case exitOk:
+		s.Status = "ok"
+	case exitPartial:
+		s.Status = "partial"
+	default:
+		s.Status = "failed"
+		s.Error = err.Error()
+	}
+
+	return code
+}
+
+func writeRunSummary(path string, summary *RunSummary) error {
+	bytes, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to convert run summary to JSON: %v", err)
+	}
+
+	if err := internal.WriteFile(path, bytes); err != nil {
+		return fmt.Errorf("Failed to write run summary: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"dev/cqb13/meteor-addon-scanner/internal"
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+func explainCommand(args []string) int {
+	flags := flag.NewFlagSet("explain", flag.ExitOnError)
+	configPath := flags.String("config", "config.json", "config file")
+	if err := parseFlags(flags, args); err != nil {
+		return exit(err)
+	}
+
+	return exit(runExplain(*configPath, flags.Arg(0)))
+}
+
+// parses a single repo and prints every request made and where each field came from
+func runExplain(configPath string, repo string) error {
+	if strings.Count(repo, "/") != 1 {
+		return configError("Repo must be in the form owner/repo: explain owner/repo")
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if err := authenticate(); err != nil {
+		return err
+	}
+
+	trace := scanner.NewTrace()
+	addon, err := scanner.TraceRepo(repo, config, trace)
+
+	for _, event := range trace.Events {
+		fmt.Printf("[%s] %s\n", event.Stage, event.Message)
+	}
+	fmt.Println()
+
+	if err != nil {
+		return fmt.Errorf("Failed to parse %s: %v", repo, err)
+	}
+
+	if addon == nil {
+		fmt.Printf("%s is a template and would be skipped\n", repo)
+		return nil
+	}
+
+	internal.InferTags([]*scanner.Addon{addon}, config)
+
+	fmt.Printf("Result:\n")
+	fmt.Printf("  Name: %s\n", addon.Name)
+	fmt.Printf("  Description: %s\n", addon.Description)
+	fmt.Printf("  Minecraft Version: %s\n", addon.McVersion)
+	fmt.Printf("  Supported Versions: %s\n", strings.Join(addon.Custom.SupportedVersions, ", "))
+	fmt.Printf("  Authors: %s\n", strings.Join(addon.Authors, ", "))
+	fmt.Printf("  Features: %d modules, %d commands, %d hud elements, %d tabs, %d themes\n",
+		len(addon.Features.Modules), len(addon.Features.Commands), len(addon.Features.HudElements), len(addon.Features.Tabs), len(addon.Features.Themes))
+	fmt.Printf("  Categories: %s\n", strings.Join(addon.Features.Categories, ", "))
+	fmt.Printf("  Topics: %s\n", strings.Join(addon.Repo.Topics, ", "))
+	fmt.Printf("  Tags: %s\n", strings.Join(addon.Custom.Tags, ", "))
+	inferred := make([]string, 0, len(addon.InferredTags))
+	for _, tag := range addon.InferredTags {
+		inferred = append(inferred, fmt.Sprintf("%s (%.2f)", tag.Tag, tag.Confidence))
+	}
+	fmt.Printf("  Inferred Tags: %s\n", strings.Join(inferred, ", "))
+	fmt.Printf("  Discord: %s\n", addon.Links.Discord)
+	fmt.Printf("  Icon: %s\n", addon.Links.Icon)
+	fmt.Printf("  Homepage: %s\n", addon.Links.Homepage)
+	fmt.Printf("  Downloads: %d\n", addon.Repo.Downloads)
+
+	return nil
+}
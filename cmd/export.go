@@ -0,0 +1,63 @@
+package main
+
+import (
+	"dev/cqb13/meteor-addon-scanner/internal"
+	"flag"
+	"log/slog"
+	"os"
+)
+
+func exportCommand(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	inputPath := flags.String("input", "", "addons written by a previous scan")
+	sqlitePath := flags.String("sqlite", "", "SQLite database the addons are exported to")
+	overwrite := flags.Bool("overwrite", false, "replace the database if it exists")
+	if err := parseFlags(flags, args); err != nil {
+		return exit(err)
+	}
+
+	return exit(runExport(*inputPath, *sqlitePath, *overwrite))
+}
+
+func runExport(inputPath string, sqlitePath string, overwrite bool) error {
+	if inputPath == "" {
+		return configError("No input file provided: export -input addons.json -sqlite addons.db")
+	}
+
+	if sqlitePath == "" {
+		return configError("No database provided: export -input addons.json -sqlite addons.db")
+	}
+
+	if err := validateSQLitePath(sqlitePath, overwrite); err != nil {
+		return err
+	}
+
+	output, err := internal.LoadOutput(inputPath)
+	if err != nil {
+		return configError("%v", err)
+	}
+
+	return exportSQLite(sqlitePath, output)
+}
+
+func validateSQLitePath(path string, overwrite bool) error {
+	if path == "" {
+		return nil
+	}
+
+	if _, err := os.Stat(path); err == nil && !overwrite {
+		return configError("Database already exists, use -overwrite to replace it")
+	}
+
+	return nil
+}
+
+func exportSQLite(path string, output *internal.Output) error {
+	if err := internal.ExportSQLite(path, output); err != nil {
+		return outputError("%v", err)
+	}
+
+	slog.Info("Exported addons to SQLite", "output", path, "addons", len(output.Addons))
+
+	return nil
+}
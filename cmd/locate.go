@@ -0,0 +1,70 @@
+package main
+
+import (
+	"dev/cqb13/meteor-addon-scanner/internal"
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"flag"
+	"log/slog"
+)
+
+func locateCommand(args []string) int {
+	flags := flag.NewFlagSet("locate", flag.ExitOnError)
+	configPath := flags.String("config", "config.json", "config file")
+	outputPath := flags.String("output", "", "file the located repos are written to, - for stdout")
+	overwrite := flags.Bool("overwrite", false, "replace the output file if it exists")
+	if err := parseFlags(flags, args); err != nil {
+		return exit(err)
+	}
+
+	return exit(runLocate(*configPath, *outputPath, *overwrite))
+}
+
+func runLocate(configPath string, outputPath string, overwrite bool) error {
+	if outputPath == "" {
+		return configError("No output file provided: locate -output repos.json")
+	}
+
+	if err := validateOutputPath(outputPath, overwrite); err != nil {
+		return configError("%v", err)
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if err := authenticate(); err != nil {
+		return err
+	}
+
+	repos, err := locateRepos(config)
+	if err != nil {
+		return err
+	}
+
+	if err := internal.SaveRepoList(outputPath, repos); err != nil {
+		return outputError("%v", err)
+	}
+
+	slog.Info("Wrote repos", "stage", "locate", "repos", len(repos), "output", outputPath)
+
+	return nil
+}
+
+// locates addon repositories and removes the blacklisted ones
+func locateRepos(config *scanner.Config) (map[string]struct{}, error) {
+	slog.Info("Locating repositories", "stage", "locate")
+	repos, err := scanner.Locate(config.VerifiedAddons.Verified)
+	if err != nil {
+		return nil, discoveryError(err)
+	}
+	slog.Info("Located repos", "stage", "locate", "repos", len(repos))
+
+	removed := internal.RemoveBlacklistedRepositories(config, repos)
+	slog.Info("Removed blacklisted repositories", "stage", "locate", "removed", removed, "blacklisted", len(config.BlacklistedRepos))
+
+	removed = internal.RemoveBlacklistedDevelopers(config, repos)
+	slog.Info("Removed repositories from blacklisted developers", "stage", "locate", "removed", removed)
+
+	return repos, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// logs and the progress line share stderr, so logs go through here to not end up in the middle of the progress line
+var stderr = &statusWriter{out: os.Stderr}
+
+// statusWriter keeps a status line below everything written to it
+type statusWriter struct {
+	mu     sync.Mutex
+	out    io.Writer
+	status string
+}
+
+// clears the status line, writes p and draws the status line again below it
+func (w *statusWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.status != "" {
+		fmt.Fprint(w.out, "\r\033[K")
+	}
+
+	n, err := w.out.Write(p)
+
+	if w.status != "" {
+		fmt.Fprint(w.out, w.status)
+	}
+
+	return n, err
+}
+
+// replaces the status line
+func (w *statusWriter) setStatus(line string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	fmt.Fprintf(w.out, "\r\033[K%s", line)
+	w.status = line
+}
+
+// leaves the status line as it is, later writes go below it
+func (w *statusWriter) endStatus() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.status != "" {
+		fmt.Fprintln(w.out)
+	}
+	w.status = ""
+}
+
+type logOptions struct {
+	format string
+	level  string
+}
+
+// registers the logging flags shared by every command
+func logFlags(flags *flag.FlagSet) *logOptions {
+	var options logOptions
+	flags.StringVar(&options.format, "log-format", "text", "log format, text or json")
+	flags.StringVar(&options.level, "log-level", "info", "minimum log level, debug, info, warn or error")
+	return &options
+}
+
+// installs the default logger used by the scanner, internal and discord packages
+func (o *logOptions) setup() error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(o.level)); err != nil {
+		return configError("Invalid log level '%s', expected debug, info, warn or error", o.level)
+	}
+
+	handlerOptions := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch strings.ToLower(o.format) {
+	case "text":
+		handler = slog.NewTextHandler(stderr, handlerOptions)
+	case "json":
+		handler = slog.NewJSONHandler(stderr, handlerOptions)
+	default:
+		return configError("Invalid log format '%s', expected text or json", o.format)
+	}
+
+	slog.SetDefault(slog.New(handler))
+
+	return nil
+}
+
+// parses the flags of a command and sets up logging from them
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	logging := logFlags(flags)
+	flags.Parse(args)
+	return logging.setup()
+}
@@ -1,202 +1,279 @@
 package main
 
 import (
-	"dev/cqb13/meteor-addon-scanner/discord"
 	"dev/cqb13/meteor-addon-scanner/internal"
 	"dev/cqb13/meteor-addon-scanner/scanner"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
-	"strconv"
+	"runtime/debug"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
-func main() {
-	startTime := time.Now()
-	args := os.Args
+// version of the scanner, set at build time with -ldflags "-X main.version=v1.2.3"
+var version = "dev"
 
-	if len(args) < 3 {
-		fmt.Println("Not enough argument provided: config.json output.json [invalid.txt]")
-		return
+// returns the version set at build time, or the commit the scanner was built from
+func scannerVersion() string {
+	if version != "dev" {
+		return version
 	}
 
-	configPath := args[1]
-	outputPath := args[2]
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return version
+	}
 
-	err := validateOutputPath(outputPath)
-	if err != nil {
-		fmt.Println(err)
-		return
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return "dev-" + setting.Value
+		}
 	}
 
-	invalidRepoLogPath := ""
-	invalidRepoLog := make(map[string]any)
-	if len(args) >= 4 {
-		invalidRepoLogPath = args[3]
+	return version
+}
+
+type command struct {
+	name        string
+	usage       string
+	description string
+	// runs the command with the arguments after its name and returns the exit code
+	run func(args []string) int
+}
 
-		ok := internal.LoadInvalidRepoLog(invalidRepoLogPath, invalidRepoLog)
-		if !ok {
-			fmt.Println("Failed to load invalid repo log")
-		} else {
-			fmt.Println("Loaded invalid repo log")
+var commands = []command{
+	{"locate", "locate -config config.json -output repos.json", "Locate addon repositories and write the list", locateCommand},
+	{"parse", "parse -config config.json -output addons.json (-repos repos.json | owner/repo)", "Parse a list of repositories or a single one into addons", parseCommand},
+	{"scan", "scan -config config.json -output addons.json [-retry-log retry-log.json]", "Locate, parse and validate addons", scanCommand},
+	{"explain", "explain -config config.json owner/repo", "Parse a single repository and trace where every field came from", explainCommand},
+	{"validate", "validate -config config.json -input addons.json [-output addons.json]", "Run the verification and suspicion checks on existing addons", validateCommand},
+	{"diff", "diff [-output changes.json] old.json new.json", "List what changed between two scans", diffCommand},
+	{"export", "export -input addons.json -sqlite addons.db", "Export addons to a SQLite database", exportCommand},
+	{"render-site", "render-site -input addons.json -output site [-base-url https://example.com]", "Render addons to a static HTML site", renderSiteCommand},
+	{"compat", "compat -input addons.json -output compat.json", "Map Minecraft versions to the addons that support them", compatCommand},
+	{"authors", "authors -input addons.json -output authors.json [-contributors]", "Group addons by author and resolve their GitHub logins", authorsCommand},
+	{"search-index", "search-index -input addons.json -output search-index.json", "Build a full-text search index of addons", searchIndexCommand},
+	{"search", "search -index search-index.json auto crystal", "Query a search index", searchCommand},
+	{"serve", "serve -input addons.json [-addr :8080]", "Serve addons over a read-only JSON api", serveCommand},
+	{"schema", "schema -output addons.schema.json", "Write the JSON Schema of the addons output", schemaCommand},
+	{"config", "config check -config config.json", "Lint a config file", configCommand},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(exitConfig)
+	}
+
+	for _, command := range commands {
+		if command.name == os.Args[1] {
+			os.Exit(command.run(os.Args[2:]))
 		}
 	}
 
-	err = internal.ValidateConfigPath(configPath)
+	if os.Args[1] != "help" && os.Args[1] != "-h" && os.Args[1] != "--help" {
+		fmt.Printf("Unknown command: %s\n", os.Args[1])
+		usage()
+		os.Exit(exitConfig)
+	}
+
+	usage()
+}
+
+func usage() {
+	fmt.Println("Usage: scanner <command> [flags]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	for _, command := range commands {
+		fmt.Printf("  %-14s %s\n", command.name, command.description)
+		fmt.Printf("  %-14s   %s\n", "", command.usage)
+	}
+	fmt.Println()
+	fmt.Println("Run 'scanner <command> -h' to list the flags of a command")
+}
+
+// reports err, if any, and returns the matching exit code
+func exit(err error) int {
+	if err != nil {
+		slog.Error(err.Error(), "exit_code", exitCode(err))
+	}
+
+	return exitCode(err)
+}
+
+func loadConfig(configPath string) (*scanner.Config, error) {
+	err := internal.ValidateConfigPath(configPath)
 	if err != nil {
-		fmt.Printf("Verified: %s\n", err)
-		return
+		return nil, configError("Verified: %s", err)
 	}
 
 	config, err := internal.LoadConfig(configPath)
 	if err != nil {
-		fmt.Printf("Failed to load config: %s\n", err)
-		return
+		return nil, configError("Failed to load config: %s", err)
 	}
 
+	// checked up front, so a scan does not fail after all repos were parsed
+	if config.Output.Sort != "" && !slices.Contains(internal.SortOrders, config.Output.Sort) {
+		return nil, configError("Unknown output.sort '%s', expected one of %s", config.Output.Sort, strings.Join(internal.SortOrders, ", "))
+	}
+
+	return config, nil
+}
+
+// returns output.pretty of the config, false without a config
+func loadPretty(configPath string) (bool, error) {
+	if configPath == "" {
+		return false, nil
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return false, err
+	}
+
+	return config.Output.Pretty, nil
+}
+
+// loads the GitHub API key from the environment and makes sure GitHub accepts it
+func authenticate() error {
 	// Load .env file
 	if _, err := os.Stat(".env"); err == nil {
 		err = godotenv.Load()
 		if err != nil {
-			fmt.Println("Failed to load env file:", err)
-			return
+			return configError("Failed to load env file: %v", err)
 		}
 	} else {
-		fmt.Println(".env file not found, assuming environment variable is set externally")
+		slog.Debug(".env file not found, assuming environment variable is set externally")
 	}
 
 	key := os.Getenv("KEY")
+	if key == "" {
+		return &exitError{exitAuth, fmt.Errorf("No GitHub API key found in KEY")}
+	}
 	scanner.InitDefaultHeaders(key)
 
-	webhookUrl := os.Getenv("WEBHOOK")
-
-	fmt.Println("Locating Repositories")
-	repos := scanner.Locate(config.VerifiedAddons.Verified)
-	fmt.Printf("Located %v repos\n", len(repos))
-
-	removed := internal.RemoveBlacklistedRepositories(config, repos)
-	fmt.Printf("Removed %d/%d repo blacklisted repositories\n", removed, len(config.BlacklistedRepos))
-
-	removed = internal.RemoveBlacklistedDevelopers(config, repos)
-	fmt.Printf("Removed %d repositories from blacklisted developers\n", removed)
+	if err := scanner.CheckAuth(); err != nil {
+		return &exitError{exitAuth, fmt.Errorf("Failed to authenticate with GitHub: %w", err)}
+	}
 
-	fmt.Println("Parsing Repositories")
-	addons := scanner.ParseRepos(repos, config, invalidRepoLog)
-	fmt.Printf("Found %d/%d valid addons\n", len(addons), len(repos))
+	return nil
+}
 
-	if config.VerifiedAddons.ValidateForks {
-		fmt.Println("Validating forked verified addons")
-		log := internal.ValidateForkedVerifiedAddons(addons)
-		for addon, status := range log {
-			fmt.Printf("\t%s: %s\n", addon, status)
-		}
+// checks that output is a json file or "-" for stdout, and that it is only replaced when overwrite is set
+func validateOutputPath(output string, overwrite bool) error {
+	if output == "-" {
+		return nil
 	}
 
-	if config.VerifiedAddons.MinMinecraftVersion != "" {
-		fmt.Println("Ensuring verified addons comply with minimum version")
-		unverifiedAddons := internal.ValidateVerifiedAddonVersions(addons, config.VerifiedAddons.MinMinecraftVersion)
-		for addon, version := range unverifiedAddons {
-			fmt.Printf("\t%s: Supports %s which is bellow the required %s version -> no longer verified\n", addon, version, config.VerifiedAddons.MinMinecraftVersion)
-		}
+	if !strings.HasSuffix(output, ".json") {
+		return fmt.Errorf("Output path must lead to a json file")
 	}
 
-	fmt.Println("Checking for suspicious addons")
-	suspicious := internal.DetectSuspiciousAddons(addons, config)
-	if len(suspicious) == 0 {
-		fmt.Println("Found no suspicious addons")
+	if _, err := os.Stat(output); err == nil && !overwrite {
+		return fmt.Errorf("Output path already exists, use -overwrite to replace it")
 	}
 
-	for repo, reasons := range suspicious {
-		fmt.Printf("\t%s: %s\n", repo, strings.Join(reasons, ", "))
+	return nil
+}
+
+// only one file can be written to stdout, otherwise their contents are mixed.
+// flags holds the name of every flag and its path
+func validateStdout(flags ...string) error {
+	var names []string
+	for i := 0; i+1 < len(flags); i += 2 {
+		if flags[i+1] == "-" {
+			names = append(names, "-"+flags[i])
+		}
 	}
 
-	// update invalid repo log, if used
-	if invalidRepoLogPath != "" {
-		file, err := os.Create(invalidRepoLogPath)
-		if err != nil {
-			fmt.Printf("Failed to create invalid repo log: %s\n", err)
-		} else {
-			for repo := range invalidRepoLog {
-				fmt.Fprintf(file, "%s\n", repo)
-			}
+	if len(names) > 1 {
+		return configError("Only one of %s can be written to stdout", strings.Join(names, ", "))
+	}
 
-			fmt.Printf("Updated invalid repo log\n")
+	return nil
+}
 
-		}
+func validateStreamPath(path string, overwrite bool) error {
+	if path == "" || path == "-" {
+		return nil
 	}
 
-	// save addons
-	jsonData, err := json.Marshal(addons)
-	if err != nil {
-		fmt.Printf("Failed to convert addons to JSON: %v\n", err)
-		return
+	if _, err := os.Stat(path); err == nil && !overwrite {
+		return configError("Stream path already exists, use -overwrite to replace it")
 	}
 
-	file, err := os.Create(outputPath)
-	if err != nil {
-		fmt.Printf("Failed to create output file: %v\n", err)
-		return
-	}
-	defer file.Close()
+	return nil
+}
 
-	_, err = file.Write(jsonData)
+// streams every addon parsed from now on to path, addons that were already parsed are written first
+func streamAddons(path string, parsed []*scanner.Addon, parseOptions *scanner.ParseOptions) (*internal.Stream, error) {
+	stream, err := internal.CreateStream(path)
 	if err != nil {
-		fmt.Println("Error writing to file:", err)
-		return
+		return nil, outputError("%v", err)
 	}
 
-	// get stats
-	archivedCount := 0
-	for _, addon := range addons {
-		if addon.Repo.Archived {
-			archivedCount++
+	write := func(addon *scanner.Addon) {
+		if err := stream.WriteAddon(addon); err != nil {
+			slog.Warn(err.Error(), "stage", "stream")
 		}
 	}
 
-	executionTime := time.Since(startTime).Seconds()
-	fmt.Printf("Statistics:\n")
-	fmt.Printf("  Valid Addons: %d\n", len(addons))
-	fmt.Printf("  Archived: %d\n", archivedCount)
-	fmt.Printf("  Invalid: %d\n", len(repos)-len(addons))
-	minutes := int(executionTime) / 60
-	seconds := int(executionTime) % 60
-	fmt.Printf("  Execution Time: %d.%02d\n", minutes, seconds)
+	for _, addon := range parsed {
+		write(addon)
+	}
+	parseOptions.OnAddon = write
 
-	if config.DiscordWebhook {
-		payload := discord.NewWebhookPayload().WithUsername("Meteor Addon Scanner").WithAvatarURl("https://meteoraddons.com/favicon-96x96.png")
+	return stream, nil
+}
 
-		now := time.Now().Format("01/02/2006")
+// sorts the addons as configured and wraps them in an output
+func newOutput(addons []*scanner.Addon, config *scanner.Config) (*internal.Output, error) {
+	if err := internal.SortAddons(addons, config.Output.Sort); err != nil {
+		return nil, configError("%v", err)
+	}
 
-		embed := discord.NewEmbed().
-			WithTitle(fmt.Sprintf("Scan results from %s", now)).
-			WithField("Repos Scanned", strconv.Itoa(len(repos)), false).
-			WithField("Valid Addons", strconv.Itoa(len(addons)), false).
-			WithField("Archived Addons", strconv.Itoa(archivedCount), false).
-			WithField("Invalid Addons", strconv.Itoa(len(repos)-len(addons)), false).
-			WithField("Execution Time", fmt.Sprintf("%dm %ds", minutes, seconds), false).
-			WithColor(0xdab2ff)
+	output, err := internal.NewOutput(addons, config, scannerVersion(), time.Now())
+	if err != nil {
+		return nil, outputError("%v", err)
+	}
 
-		payload.AddEmbed(embed)
+	return output, nil
+}
 
-		_, err := discord.SendWebhookPayload(payload, webhookUrl)
-		if err != nil {
-			fmt.Println("Failed to send webhook payload: ", err)
-		}
+func writeAddons(outputPath string, addons []*scanner.Addon, config *scanner.Config, legacyArray bool) error {
+	output, err := newOutput(addons, config)
+	if err != nil {
+		return err
 	}
 
-	fmt.Println("Done!")
+	return writeOutput(outputPath, output, config, legacyArray)
 }
 
-func validateOutputPath(output string) error {
-	if !strings.HasSuffix(output, ".json") {
-		return fmt.Errorf("Output path must lead to a json file")
+// writes the output to outputPath, or only its addons if legacyArray is set
+func writeOutput(outputPath string, output *internal.Output, config *scanner.Config, legacyArray bool) error {
+	var data any = output
+	if legacyArray {
+		data = output.Addons
+	}
+
+	var jsonData []byte
+	var err error
+	if config.Output.Pretty {
+		jsonData, err = json.MarshalIndent(data, "", "  ")
+	} else {
+		jsonData, err = json.Marshal(data)
+	}
+	if err != nil {
+		return outputError("Failed to convert addons to JSON: %v", err)
 	}
 
-	if _, err := os.Stat(output); err == nil {
-		return fmt.Errorf("Output path already exists")
+	if err := internal.WriteFile(outputPath, jsonData); err != nil {
+		return outputError("Failed to write output file: %v", err)
 	}
 
 	return nil
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"dev/cqb13/meteor-addon-scanner/internal"
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+func parseCommand(args []string) int {
+	flags := flag.NewFlagSet("parse", flag.ExitOnError)
+	configPath := flags.String("config", "config.json", "config file")
+	reposPath := flags.String("repos", "", "repo list written by the locate command")
+	outputPath := flags.String("output", "", "file the addons are written to, - for stdout")
+	overwrite := flags.Bool("overwrite", false, "replace the output file if it exists")
+	legacyArray := flags.Bool("legacy-array", false, "write the addons as a bare array instead of wrapped in an output")
+	streamPath := flags.String("stream", "", "file every addon is written to as NDJSON as soon as it is parsed, - for stdout, optional")
+	retryLogPath := flags.String("retry-log", "", "retry log of repos that failed to parse")
+	statePath := flags.String("state", "", "scan state file, repos whose HEAD commit and releases did not change are not parsed again")
+	progressMode := flags.String("progress", "auto", "how progress is reported: auto, tty for a single updating line, log for periodic log lines or off")
+	if err := parseFlags(flags, args); err != nil {
+		return exit(err)
+	}
+
+	return exit(runParse(*configPath, *reposPath, flags.Arg(0), *outputPath, *overwrite, *legacyArray, *streamPath, *retryLogPath, *statePath, *progressMode))
+}
+
+func runParse(configPath string, reposPath string, repo string, outputPath string, overwrite bool, legacyArray bool, streamPath string, retryLogPath string, statePath string, progressMode string) error {
+	if (reposPath == "") == (repo == "") {
+		return configError("Provide either a repo list or a single repo: parse -output addons.json (-repos repos.json | owner/repo)")
+	}
+
+	if repo != "" && strings.Count(repo, "/") != 1 {
+		return configError("Repo must be in the form owner/repo")
+	}
+
+	if outputPath == "" {
+		return configError("No output file provided: parse -output addons.json")
+	}
+
+	if err := validateOutputPath(outputPath, overwrite); err != nil {
+		return configError("%v", err)
+	}
+
+	if err := validateProgressMode(progressMode); err != nil {
+		return err
+	}
+
+	if err := validateStreamPath(streamPath, overwrite); err != nil {
+		return err
+	}
+
+	if err := validateStdout("output", outputPath, "stream", streamPath); err != nil {
+		return err
+	}
+
+	repos := map[string]struct{}{repo: {}}
+	if reposPath != "" {
+		var err error
+		repos, err = internal.LoadRepoList(reposPath)
+		if err != nil {
+			return configError("%v", err)
+		}
+	}
+
+	retryLog := scanner.NewRetryLog()
+	if retryLogPath != "" {
+		var err error
+		retryLog, err = internal.LoadRetryLog(retryLogPath)
+		if err != nil {
+			return configError("%v", err)
+		}
+	}
+
+	var state *scanner.State
+	if statePath != "" {
+		var err error
+		state, err = internal.LoadState(statePath)
+		if err != nil {
+			return configError("%v", err)
+		}
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if err := authenticate(); err != nil {
+		return err
+	}
+
+	slog.Info("Parsing repositories", "stage", "parse", "repos", len(repos))
+	parseOptions := scanner.ParseOptions{RetryLog: retryLog, State: state, Progress: scanner.NewProgress()}
+
+	var stream *internal.Stream
+	if streamPath != "" {
+		stream, err = streamAddons(streamPath, nil, &parseOptions)
+		if err != nil {
+			return err
+		}
+	}
+
+	stopProgress, err := reportProgress(progressMode, parseOptions.Progress)
+	if err != nil {
+		return err
+	}
+	addons := scanner.ParseRepos(repos, config, parseOptions)
+	stopProgress()
+
+	if stream != nil {
+		if err := stream.Close(len(repos), time.Now()); err != nil {
+			slog.Warn(err.Error(), "stage", "stream")
+		}
+	}
+	slog.Info("Found valid addons", "stage", "parse", "addons", len(addons), "repos", len(repos))
+
+	if retryLogPath != "" {
+		if err := internal.SaveRetryLog(retryLogPath, retryLog); err != nil {
+			slog.Warn(err.Error())
+		}
+	}
+
+	if state != nil {
+		if err := internal.SaveState(statePath, state); err != nil {
+			slog.Warn(err.Error())
+		}
+	}
+
+	// without a history store the trending score is 0, like a scan without -history
+	internal.ScoreAddons(addons, nil, time.Now())
+	internal.InferTags(addons, config)
+
+	if err := writeAddons(outputPath, addons, config, legacyArray); err != nil {
+		return err
+	}
+
+	if failures := retryLog.Failures(repos, scanner.Transient); len(failures) != 0 {
+		return &exitError{exitPartial, fmt.Errorf("%d repos failed transiently: %s", len(failures), strings.Join(failures, ", "))}
+	}
+
+	return nil
+}
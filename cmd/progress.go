@@ -0,0 +1,132 @@
+package main
+
+import (
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+)
+
+// how often the progress line is redrawn on a terminal and logged otherwise
+const (
+	ttyProgressInterval = 500 * time.Millisecond
+	logProgressInterval = 30 * time.Second
+)
+
+var progressModes = []string{"auto", "tty", "log", "off"}
+
+func validateProgressMode(mode string) error {
+	if !slices.Contains(progressModes, mode) {
+		return configError("Invalid progress mode '%s', expected auto, tty, log or off", mode)
+	}
+
+	return nil
+}
+
+// starts reporting progress as a single line on a terminal or as periodic log lines,
+// mode is auto, tty, log or off. Returns a function that stops reporting
+func reportProgress(mode string, progress *scanner.Progress) (func(), error) {
+	if mode == "auto" {
+		mode = "log"
+		if isTerminal(os.Stderr) {
+			mode = "tty"
+		}
+	}
+
+	var interval time.Duration
+	var report func(scanner.ProgressSnapshot)
+	switch mode {
+	case "off":
+		return func() {}, nil
+	case "tty":
+		interval = ttyProgressInterval
+		report = func(snapshot scanner.ProgressSnapshot) {
+			stderr.setStatus(progressLine(snapshot))
+		}
+	case "log":
+		interval = logProgressInterval
+		report = logProgress
+	default:
+		return nil, validateProgressMode(mode)
+	}
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				report(progress.Snapshot())
+			case <-stop:
+				report(progress.Snapshot())
+				if mode == "tty" {
+					stderr.endStatus()
+				}
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-stopped
+	}, nil
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func progressLine(snapshot scanner.ProgressSnapshot) string {
+	percent := 0
+	if snapshot.Total != 0 {
+		percent = snapshot.Done * 100 / snapshot.Total
+	}
+
+	line := fmt.Sprintf("[%d/%d %d%%] valid %d, invalid %d, skipped %d | core %d, search %d remaining",
+		snapshot.Done, snapshot.Total, percent,
+		snapshot.Valid, snapshot.Invalid, snapshot.Skipped,
+		snapshot.Core.Remaining, snapshot.Search.Remaining)
+
+	if snapshot.Sleeping != "" {
+		line += fmt.Sprintf(" | sleeping for %s, %s left", snapshot.Sleeping, time.Until(snapshot.SleepingUntil).Round(time.Second))
+	}
+
+	if snapshot.ETA > 0 {
+		line += fmt.Sprintf(" | ETA %s", snapshot.ETA.Round(time.Second))
+	}
+
+	return line
+}
+
+func logProgress(snapshot scanner.ProgressSnapshot) {
+	attrs := []any{
+		"stage", "parse",
+		"done", snapshot.Done,
+		"total", snapshot.Total,
+		"valid", snapshot.Valid,
+		"invalid", snapshot.Invalid,
+		"skipped", snapshot.Skipped,
+		"core_remaining", snapshot.Core.Remaining,
+		"search_remaining", snapshot.Search.Remaining,
+		"eta", snapshot.ETA.Round(time.Second),
+	}
+
+	if snapshot.Sleeping != "" {
+		attrs = append(attrs, "sleeping", snapshot.Sleeping, "sleeping_until", snapshot.SleepingUntil.Format(time.TimeOnly))
+	}
+
+	slog.Info("Progress", attrs...)
+}
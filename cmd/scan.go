@@ -0,0 +1,460 @@
+package main
+
+import (
+	"dev/cqb13/meteor-addon-scanner/discord"
+	"dev/cqb13/meteor-addon-scanner/internal"
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+)
+
+// how often the progress of the scan is saved when a checkpoint file is given
+const checkpointInterval = time.Minute
+
+type scanOptions struct {
+	configPath        string
+	outputPath        string
+	overwrite         bool
+	legacyArray       bool
+	splitDir          string
+	streamPath        string
+	sqlitePath        string
+	searchIndexPath   string
+	compatPath        string
+	authorsPath       string
+	contributors      bool
+	retryLogPath      string
+	summaryPath       string
+	previousPath      string
+	changesPath       string
+	feedsDir          string
+	statePath         string
+	checkpointPath    string
+	resume            bool
+	historyPath       string
+	historyOutputPath string
+	progress          string
+}
+
+func scanCommand(args []string) int {
+	startTime := time.Now()
+
+	var options scanOptions
+	flags := flag.NewFlagSet("scan", flag.ExitOnError)
+	flags.StringVar(&options.configPath, "config", "config.json", "config file")
+	flags.StringVar(&options.outputPath, "output", "", "file the addons are written to, - for stdout")
+	flags.BoolVar(&options.overwrite, "overwrite", false, "replace the output file if it exists")
+	flags.StringVar(&options.splitDir, "split-dir", "", "directory an index.json and one addons/<owner>/<repo>.json per addon are written to, optional")
+	flags.StringVar(&options.streamPath, "stream", "", "file every addon is written to as NDJSON as soon as it is parsed, - for stdout, optional")
+	flags.StringVar(&options.sqlitePath, "sqlite", "", "SQLite database the addons are exported to, optional")
+	flags.StringVar(&options.searchIndexPath, "search-index", "", "file a full-text search index of the addons is written to, optional")
+	flags.StringVar(&options.compatPath, "compat", "", "file the Minecraft version compatibility matrix is written to, optional")
+	flags.StringVar(&options.authorsPath, "authors", "", "file the author index is written to, optional")
+	flags.BoolVar(&options.contributors, "contributors", false, "match authors against the contributors of their repos, one or more requests per repo, requires -authors")
+	flags.BoolVar(&options.legacyArray, "legacy-array", false, "write the addons as a bare array instead of wrapped in an output")
+	flags.StringVar(&options.retryLogPath, "retry-log", "", "retry log of repos that failed to parse, failed repos are backed off instead of being parsed every scan")
+	flags.StringVar(&options.summaryPath, "summary", "", "file a machine readable summary of the run is written to")
+	flags.StringVar(&options.previousPath, "previous", "", "addons.json from the previous scan, used to keep entries of repos that fail transiently")
+	flags.StringVar(&options.changesPath, "changes", "", "file the changes since the previous scan are written to, requires -previous")
+	flags.StringVar(&options.feedsDir, "feeds", "", "directory the Atom feeds of new addons, releases and verified addons are updated in, requires -previous")
+	flags.StringVar(&options.statePath, "state", "", "scan state file, repos whose HEAD commit and releases did not change are not parsed again")
+	flags.StringVar(&options.checkpointPath, "checkpoint", "", "file the progress of the scan is periodically saved to")
+	flags.BoolVar(&options.resume, "resume", false, "resume an interrupted scan from the checkpoint file")
+	flags.StringVar(&options.historyPath, "history", "", "history store the stars, forks, downloads and feature count of every addon are appended to")
+	flags.StringVar(&options.progress, "progress", "auto", "how progress is reported: auto, tty for a single updating line, log for periodic log lines or off")
+	flags.StringVar(&options.historyOutputPath, "history-output", "", "file the recent history and 7 and 30 day deltas of every addon are written to, requires -history")
+	if err := parseFlags(flags, args); err != nil {
+		return exit(err)
+	}
+
+	summary := newRunSummary(startTime)
+
+	err := runScan(options, summary)
+	code := summary.finish(err)
+	if err != nil {
+		slog.Error(err.Error(), "exit_code", code)
+	}
+
+	if options.summaryPath != "" {
+		if err := writeRunSummary(options.summaryPath, summary); err != nil {
+			slog.Error(err.Error())
+			if code == exitOk || code == exitPartial {
+				code = exitOutput
+			}
+		}
+	}
+
+	return code
+}
+
+func runScan(options scanOptions, summary *RunSummary) error {
+	startTime := summary.StartedAt
+
+	if options.outputPath == "" {
+		return configError("No output file provided: scan -output addons.json")
+	}
+
+	if options.resume && options.checkpointPath == "" {
+		return configError("Resuming requires a checkpoint file")
+	}
+
+	if options.changesPath != "" && options.previousPath == "" {
+		return configError("Writing the changes requires the previous addons")
+	}
+
+	if options.feedsDir != "" && options.previousPath == "" {
+		return configError("Updating the feeds requires the previous addons")
+	}
+
+	if options.contributors && options.authorsPath == "" {
+		return configError("Matching contributors requires an author index")
+	}
+
+	if options.historyOutputPath != "" && options.historyPath == "" {
+		return configError("Writing the history output requires a history store")
+	}
+
+	if err := validateProgressMode(options.progress); err != nil {
+		return err
+	}
+
+	if err := validateStreamPath(options.streamPath, options.overwrite); err != nil {
+		return err
+	}
+
+	if err := validateStdout("output", options.outputPath, "stream", options.streamPath, "compat", options.compatPath,
+		"authors", options.authorsPath, "search-index", options.searchIndexPath); err != nil {
+		return err
+	}
+
+	if err := validateSQLitePath(options.sqlitePath, options.overwrite); err != nil {
+		return err
+	}
+
+	err := validateOutputPath(options.outputPath, options.overwrite)
+	if err != nil {
+		return configError("%v", err)
+	}
+
+	if options.compatPath != "" {
+		if err := validateOutputPath(options.compatPath, options.overwrite); err != nil {
+			return configError("Compatibility matrix: %v", err)
+		}
+	}
+
+	if options.authorsPath != "" {
+		if err := validateOutputPath(options.authorsPath, options.overwrite); err != nil {
+			return configError("Author index: %v", err)
+		}
+	}
+
+	if options.searchIndexPath != "" {
+		if err := validateOutputPath(options.searchIndexPath, options.overwrite); err != nil {
+			return configError("Search index: %v", err)
+		}
+	}
+
+	retryLog := scanner.NewRetryLog()
+	if options.retryLogPath != "" {
+		retryLog, err = internal.LoadRetryLog(options.retryLogPath)
+		if err != nil {
+			return configError("%v", err)
+		}
+		slog.Info("Loaded retry log", "entries", len(retryLog.Entries))
+	}
+
+	var state *scanner.State
+	if options.statePath != "" {
+		state, err = internal.LoadState(options.statePath)
+		if err != nil {
+			return configError("%v", err)
+		}
+		slog.Info("Loaded scan state", "repos", len(state.Repos))
+	}
+
+	config, err := loadConfig(options.configPath)
+	if err != nil {
+		return err
+	}
+
+	if err := authenticate(); err != nil {
+		return err
+	}
+
+	webhookUrl := os.Getenv("WEBHOOK")
+
+	var repos map[string]struct{}
+	var checkpoint *scanner.Checkpoint
+	if options.resume {
+		checkpoint, err = internal.LoadCheckpoint(options.checkpointPath)
+		if err != nil {
+			return configError("%v", err)
+		}
+
+		repos = checkpoint.Repos()
+		slog.Info("Loaded checkpoint", "done", len(checkpoint.Done), "repos", len(repos))
+	} else {
+		repos, err = locateRepos(config)
+		if err != nil {
+			return err
+		}
+	}
+
+	summary.ReposLocated = len(repos)
+
+	parseOptions := scanner.ParseOptions{
+		RetryLog: retryLog,
+		State:    state,
+		Resume:   checkpoint,
+		Progress: scanner.NewProgress(),
+	}
+
+	if options.checkpointPath != "" {
+		parseOptions.CheckpointInterval = checkpointInterval
+		parseOptions.OnCheckpoint = func(checkpoint *scanner.Checkpoint) {
+			// stores first, so the checkpoint never refers to progress they are missing
+			if options.retryLogPath != "" {
+				if err := internal.SaveRetryLog(options.retryLogPath, retryLog); err != nil {
+					slog.Warn(err.Error(), "stage", "checkpoint")
+					return
+				}
+			}
+
+			if state != nil {
+				if err := internal.SaveState(options.statePath, state); err != nil {
+					slog.Warn(err.Error(), "stage", "checkpoint")
+					return
+				}
+			}
+
+			if err := internal.SaveCheckpoint(options.checkpointPath, checkpoint); err != nil {
+				slog.Warn(err.Error(), "stage", "checkpoint")
+				return
+			}
+
+			slog.Info("Saved checkpoint", "stage", "checkpoint", "done", len(checkpoint.Done), "repos", len(repos))
+		}
+	}
+
+	slog.Info("Parsing repositories", "stage", "parse", "repos", len(repos))
+	var stream *internal.Stream
+	if options.streamPath != "" {
+		var resumed []*scanner.Addon
+		if checkpoint != nil {
+			resumed = checkpoint.Addons
+		}
+
+		stream, err = streamAddons(options.streamPath, resumed, &parseOptions)
+		if err != nil {
+			return err
+		}
+	}
+
+	stopProgress, err := reportProgress(options.progress, parseOptions.Progress)
+	if err != nil {
+		return err
+	}
+	addons := scanner.ParseRepos(repos, config, parseOptions)
+	stopProgress()
+
+	if stream != nil {
+		if err := stream.Close(len(repos), time.Now()); err != nil {
+			summary.warn("%v", err)
+		}
+	}
+	slog.Info("Found valid addons", "stage", "parse", "addons", len(addons), "repos", len(repos))
+
+	summary.TransientFailures = retryLog.Failures(repos, scanner.Transient)
+
+	var previous []*scanner.Addon
+	if options.previousPath != "" {
+		slog.Info("Carrying forward addons that failed transiently", "stage", "stale")
+		previous, err = internal.LoadAddons(options.previousPath)
+		if err != nil {
+			summary.warn("%v", err)
+		} else {
+			addons = internal.CarryForwardStaleAddons(addons, previous, retryLog, config.StaleRetentionDays, startTime)
+		}
+	}
+
+	validateAddons(addons, config)
+
+	// update history, if used
+	var history internal.History
+	if options.historyPath != "" {
+		history, err = internal.LoadHistory(options.historyPath)
+		if err != nil {
+			summary.warn("%v", err)
+		} else {
+			history.Record(addons, config.History.RetentionDays, startTime)
+
+			if err := internal.SaveHistory(options.historyPath, history); err != nil {
+				summary.warn("%v", err)
+			} else {
+				slog.Info("Updated history", "stage", "history")
+			}
+
+			if options.historyOutputPath != "" {
+				historySummary := history.Summarize(addons, config.History.Points, startTime)
+				if err := internal.SaveHistorySummary(options.historyOutputPath, historySummary); err != nil {
+					summary.warn("%v", err)
+				} else {
+					slog.Info("Wrote history", "stage", "history", "addons", len(historySummary))
+				}
+			}
+		}
+	}
+
+	slog.Info("Scoring addons", "stage", "score")
+	internal.ScoreAddons(addons, history, startTime)
+
+	slog.Info("Inferring tags", "stage", "tags")
+	internal.InferTags(addons, config)
+
+	// only written when the previous addons loaded, an empty diff would list every addon as added
+	if (options.changesPath != "" || options.feedsDir != "") && previous != nil {
+		changes := internal.DiffAddons(previous, addons, config, retryLog, startTime)
+		logChanges(changes)
+
+		if options.changesPath != "" {
+			if err := internal.SaveChanges(options.changesPath, changes); err != nil {
+				summary.warn("%v", err)
+			}
+		}
+
+		if options.feedsDir != "" {
+			if err := internal.UpdateFeeds(options.feedsDir, changes, addons, history, config, startTime); err != nil {
+				summary.warn("%v", err)
+			} else {
+				slog.Info("Updated feeds", "stage", "feeds", "output", options.feedsDir)
+			}
+		}
+	}
+
+	// update retry log, if used
+	if options.retryLogPath != "" {
+		err := internal.SaveRetryLog(options.retryLogPath, retryLog)
+		if err != nil {
+			summary.warn("%v", err)
+		} else {
+			slog.Info("Updated retry log")
+		}
+	}
+
+	// update scan state, if used
+	if state != nil {
+		err := internal.SaveState(options.statePath, state)
+		if err != nil {
+			summary.warn("%v", err)
+		} else {
+			slog.Info("Updated scan state")
+		}
+	}
+
+	output, err := newOutput(addons, config)
+	if err != nil {
+		return err
+	}
+
+	if err := writeOutput(options.outputPath, output, config, options.legacyArray); err != nil {
+		return err
+	}
+
+	if options.splitDir != "" {
+		if err := internal.WriteSplitOutput(options.splitDir, output, config.Output.Pretty); err != nil {
+			return outputError("%v", err)
+		}
+		slog.Info("Wrote split output", "output", options.splitDir, "addons", len(output.Addons))
+	}
+
+	if options.sqlitePath != "" {
+		if err := exportSQLite(options.sqlitePath, output); err != nil {
+			return err
+		}
+	}
+
+	if options.searchIndexPath != "" {
+		if err := writeSearchIndex(options.searchIndexPath, output); err != nil {
+			return err
+		}
+	}
+
+	if options.compatPath != "" {
+		if err := writeCompatibility(options.compatPath, output, config.Output.Pretty); err != nil {
+			return err
+		}
+	}
+
+	if options.authorsPath != "" {
+		if err := writeAuthorIndex(options.authorsPath, output, config.Output.Pretty, options.contributors); err != nil {
+			return err
+		}
+	}
+
+	// the scan finished, so there is nothing left to resume
+	if options.checkpointPath != "" {
+		if err := os.Remove(options.checkpointPath); err != nil && !os.IsNotExist(err) {
+			slog.Warn("Failed to remove checkpoint", "error", err)
+		}
+	}
+
+	// get stats
+	archivedCount := 0
+	staleCount := 0
+	for _, addon := range addons {
+		if addon.Repo.Archived {
+			archivedCount++
+		}
+		if addon.Stale {
+			staleCount++
+		}
+	}
+
+	summary.ValidAddons = len(addons)
+	summary.InvalidRepos = len(repos) - len(addons)
+	summary.ArchivedAddons = archivedCount
+	summary.StaleAddons = staleCount
+
+	executionTime := time.Since(startTime).Seconds()
+	minutes := int(executionTime) / 60
+	seconds := int(executionTime) % 60
+	slog.Info("Statistics",
+		"valid", len(addons),
+		"archived", archivedCount,
+		"stale", staleCount,
+		"invalid", len(repos)-len(addons),
+		"transient_failures", len(summary.TransientFailures),
+		"execution_time", fmt.Sprintf("%d.%02d", minutes, seconds))
+
+	if config.DiscordWebhook {
+		payload := discord.NewWebhookPayload().WithUsername("Meteor Addon Scanner").WithAvatarURl("https://meteoraddons.com/favicon-96x96.png")
+
+		now := time.Now().Format("01/02/2006")
+
+		embed := discord.NewEmbed().
+			WithTitle(fmt.Sprintf("Scan results from %s", now)).
+			WithField("Repos Scanned", strconv.Itoa(len(repos)), false).
+			WithField("Valid Addons", strconv.Itoa(len(addons)), false).
+			WithField("Archived Addons", strconv.Itoa(archivedCount), false).
+			WithField("Stale Addons", strconv.Itoa(staleCount), false).
+			WithField("Invalid Addons", strconv.Itoa(len(repos)-len(addons)), false).
+			WithField("Transient Failures", strconv.Itoa(len(summary.TransientFailures)), false).
+			WithField("Execution Time", fmt.Sprintf("%dm %ds", minutes, seconds), false).
+			WithColor(0xdab2ff)
+
+		payload.AddEmbed(embed)
+
+		_, err := discord.SendWebhookPayload(payload, webhookUrl)
+		if err != nil {
+			slog.Warn("Failed to send webhook payload", "stage", "discord", "error", err)
+		}
+	}
+
+	slog.Info("Done!")
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"dev/cqb13/meteor-addon-scanner/internal"
+	"encoding/json"
+	"flag"
+	"log/slog"
+)
+
+func schemaCommand(args []string) int {
+	flags := flag.NewFlagSet("schema", flag.ExitOnError)
+	outputPath := flags.String("output", "-", "file the schema is written to, - for stdout")
+	overwrite := flags.Bool("overwrite", false, "replace the output file if it exists")
+	if err := parseFlags(flags, args); err != nil {
+		return exit(err)
+	}
+
+	return exit(runSchema(*outputPath, *overwrite))
+}
+
+func runSchema(outputPath string, overwrite bool) error {
+	if err := validateOutputPath(outputPath, overwrite); err != nil {
+		return configError("%v", err)
+	}
+
+	schema := internal.Schema(internal.Output{}, "Meteor addons")
+
+	bytes, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return outputError("Failed to convert schema to JSON: %v", err)
+	}
+
+	if err := internal.WriteFile(outputPath, bytes); err != nil {
+		return outputError("Failed to write schema: %v", err)
+	}
+
+	slog.Debug("Wrote schema", "output", outputPath, "schema_version", internal.SchemaVersion)
+
+	return nil
+}
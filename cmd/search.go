@@ -0,0 +1,83 @@
+package main
+
+import (
+	"dev/cqb13/meteor-addon-scanner/internal"
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+func searchIndexCommand(args []string) int {
+	flags := flag.NewFlagSet("search-index", flag.ExitOnError)
+	inputPath := flags.String("input", "", "addons written by a previous scan")
+	outputPath := flags.String("output", "", "file the search index is written to, - for stdout")
+	overwrite := flags.Bool("overwrite", false, "replace the output file if it exists")
+	if err := parseFlags(flags, args); err != nil {
+		return exit(err)
+	}
+
+	return exit(runSearchIndex(*inputPath, *outputPath, *overwrite))
+}
+
+func runSearchIndex(inputPath string, outputPath string, overwrite bool) error {
+	if inputPath == "" || outputPath == "" {
+		return configError("No input or output file provided: search-index -input addons.json -output search-index.json")
+	}
+
+	if err := validateOutputPath(outputPath, overwrite); err != nil {
+		return configError("%v", err)
+	}
+
+	output, err := internal.LoadOutput(inputPath)
+	if err != nil {
+		return configError("%v", err)
+	}
+
+	return writeSearchIndex(outputPath, output)
+}
+
+func writeSearchIndex(path string, output *internal.Output) error {
+	index := internal.BuildSearchIndex(output.Addons)
+	if err := internal.SaveSearchIndex(path, index); err != nil {
+		return outputError("%v", err)
+	}
+
+	slog.Info("Wrote search index", "output", path, "addons", len(index.Docs), "terms", len(index.Terms))
+
+	return nil
+}
+
+func searchCommand(args []string) int {
+	flags := flag.NewFlagSet("search", flag.ExitOnError)
+	indexPath := flags.String("index", "", "search index written by search-index or scan -search-index")
+	limit := flags.Int("limit", 10, "maximum number of results")
+	if err := parseFlags(flags, args); err != nil {
+		return exit(err)
+	}
+
+	return exit(runSearch(*indexPath, strings.Join(flags.Args(), " "), *limit))
+}
+
+func runSearch(indexPath string, query string, limit int) error {
+	if indexPath == "" || strings.TrimSpace(query) == "" {
+		return configError("No search index or query provided: search -index search-index.json auto crystal")
+	}
+
+	index, err := internal.LoadSearchIndex(indexPath)
+	if err != nil {
+		return configError("%v", err)
+	}
+
+	hits := index.Query(query, limit)
+	if len(hits) == 0 {
+		fmt.Println("No addons found")
+		return nil
+	}
+
+	for _, hit := range hits {
+		fmt.Printf("%8.3f  %s (%s)\n", hit.Score, hit.Name, hit.Id)
+	}
+
+	return nil
+}
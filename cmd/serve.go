@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"dev/cqb13/meteor-addon-scanner/internal"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func serveCommand(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	inputPath := flags.String("input", "", "addons written by a scan, reloaded when the file changes")
+	address := flags.String("addr", ":8080", "address the api listens on")
+	reloadInterval := flags.Duration("reload-interval", 5*time.Second, "how often the input is checked for changes")
+	if err := parseFlags(flags, args); err != nil {
+		return exit(err)
+	}
+
+	return exit(runServe(*inputPath, *address, *reloadInterval))
+}
+
+func runServe(inputPath string, address string, reloadInterval time.Duration) error {
+	if inputPath == "" {
+		return configError("No input file provided: serve -input addons.json")
+	}
+
+	if reloadInterval <= 0 {
+		return configError("Reload interval must be positive")
+	}
+
+	server, err := internal.NewServer(inputPath)
+	if err != nil {
+		return configError("%v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go server.Watch(reloadInterval, ctx.Done())
+
+	httpServer := &http.Server{
+		Addr:              address,
+		Handler:           server.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("Serving addons", "stage", "serve", "address", address, "input", inputPath)
+
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("Failed to serve: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"dev/cqb13/meteor-addon-scanner/internal"
+	"flag"
+	"log/slog"
+)
+
+func renderSiteCommand(args []string) int {
+	flags := flag.NewFlagSet("render-site", flag.ExitOnError)
+	inputPath := flags.String("input", "", "addons written by a previous scan")
+	outputDir := flags.String("output", "", "directory the site is written to")
+	baseURL := flags.String("base-url", "", "url the site is published at, used for the sitemap, optional")
+	if err := parseFlags(flags, args); err != nil {
+		return exit(err)
+	}
+
+	return exit(runRenderSite(*inputPath, *outputDir, *baseURL))
+}
+
+func runRenderSite(inputPath string, outputDir string, baseURL string) error {
+	if inputPath == "" || outputDir == "" {
+		return configError("No input file or output directory provided: render-site -input addons.json -output site")
+	}
+
+	output, err := internal.LoadOutput(inputPath)
+	if err != nil {
+		return configError("%v", err)
+	}
+
+	if err := internal.RenderSite(outputDir, output, baseURL); err != nil {
+		return outputError("%v", err)
+	}
+
+	if baseURL == "" {
+		slog.Warn("No base url provided, skipped the sitemap", "stage", "site")
+	}
+
+	slog.Info("Rendered site", "stage", "site", "output", outputDir, "addons", len(output.Addons))
+
+	return nil
+}
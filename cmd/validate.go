@@ -0,0 +1,86 @@
+package main
+
+import (
+	"dev/cqb13/meteor-addon-scanner/internal"
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"flag"
+	"log/slog"
+	"strings"
+)
+
+func validateCommand(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	configPath := flags.String("config", "config.json", "config file")
+	inputPath := flags.String("input", "", "addons written by a previous scan")
+	outputPath := flags.String("output", "", "file the addons are written to with their updated verification, - for stdout, optional")
+	overwrite := flags.Bool("overwrite", false, "replace the output file if it exists")
+	legacyArray := flags.Bool("legacy-array", false, "write the addons as a bare array instead of wrapped in an output")
+	if err := parseFlags(flags, args); err != nil {
+		return exit(err)
+	}
+
+	return exit(runValidate(*configPath, *inputPath, *outputPath, *overwrite, *legacyArray))
+}
+
+func runValidate(configPath string, inputPath string, outputPath string, overwrite bool, legacyArray bool) error {
+	if inputPath == "" {
+		return configError("No input file provided: validate -input addons.json")
+	}
+
+	if outputPath != "" {
+		if err := validateOutputPath(outputPath, overwrite); err != nil {
+			return configError("%v", err)
+		}
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	addons, err := internal.LoadAddons(inputPath)
+	if err != nil {
+		return configError("%v", err)
+	}
+
+	// only validating forks requires talking to GitHub
+	if config.VerifiedAddons.ValidateForks {
+		if err := authenticate(); err != nil {
+			return err
+		}
+	}
+
+	validateAddons(addons, config)
+
+	if outputPath != "" {
+		return writeAddons(outputPath, addons, config, legacyArray)
+	}
+
+	return nil
+}
+
+// runs the verification and suspicion checks, addons that fail verification are no longer verified
+func validateAddons(addons []*scanner.Addon, config *scanner.Config) {
+	if config.VerifiedAddons.ValidateForks {
+		slog.Info("Validating forked verified addons", "stage", "validate")
+		internal.ValidateForkedVerifiedAddons(addons)
+	}
+
+	if config.VerifiedAddons.MinMinecraftVersion != "" {
+		slog.Info("Ensuring verified addons comply with minimum version", "stage", "validate")
+		unverifiedAddons := internal.ValidateVerifiedAddonVersions(addons, config.VerifiedAddons.MinMinecraftVersion)
+		for addon, version := range unverifiedAddons {
+			slog.Info("Supported version is below the minimum, no longer verified", "repo", addon, "stage", "validate", "version", version, "minimum", config.VerifiedAddons.MinMinecraftVersion)
+		}
+	}
+
+	slog.Info("Checking for suspicious addons", "stage", "validate")
+	suspicious := internal.DetectSuspiciousAddons(addons, config)
+	if len(suspicious) == 0 {
+		slog.Info("Found no suspicious addons", "stage", "validate")
+	}
+
+	for repo, reasons := range suspicious {
+		slog.Warn("Suspicious addon", "repo", repo, "stage", "validate", "reasons", strings.Join(reasons, ", "))
+	}
+}
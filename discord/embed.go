@@ -3,6 +3,7 @@ package discord
 import (
 	"bytes"
 	"encoding/json"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
@@ -171,5 +172,7 @@ func SendWebhookPayload(payload *WebhookPayload, url string) (*http.Response, er
 		return nil, err
 	}
 
+	slog.Debug("Sent webhook payload", "stage", "discord", "status", resp.StatusCode, "embeds", len(payload.Embeds))
+
 	return resp, nil
 }
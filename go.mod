@@ -2,4 +2,7 @@ module dev/cqb13/meteor-addon-scanner
 
 go 1.24.1
 
-require github.com/joho/godotenv v1.5.1
+require (
+	github.com/joho/godotenv v1.5.1
+	github.com/mattn/go-sqlite3 v1.14.52
+)
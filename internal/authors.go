@@ -0,0 +1,216 @@
+package internal
+
+import (
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// contributors are fetched 100 per page, repos with more than this many pages only have their top contributors matched
+const maxContributorPages = 5
+
+// where the GitHub login of an author was found
+const (
+	LoginSourceOwner       = "owner"
+	LoginSourceContributor = "contributor"
+)
+
+// AuthorIndex lists every author named by an addon, addons without authors are listed under the owner of their repo
+type AuthorIndex struct {
+	GeneratedAt string   `json:"generated_at"`
+	Authors     []Author `json:"authors"`
+}
+
+type Author struct {
+	// the most used spelling of the name
+	Name string `json:"name"`
+	// other spellings of the name that only differ in case or whitespace
+	Aliases []string `json:"aliases"`
+	// GitHub login, empty if it could not be resolved
+	Login string `json:"login"`
+	// owner or contributor, empty if the login could not be resolved
+	LoginSource string   `json:"login_source"`
+	AvatarURL   string   `json:"avatar_url"`
+	Addons      []string `json:"addons"`
+	Stars       int      `json:"stars"`
+	Downloads   int      `json:"downloads"`
+}
+
+type contributor struct {
+	Login string `json:"login"`
+	Type  string `json:"type"`
+}
+
+// an author while the index is built, along with how often each spelling of the name was used
+type authorEntry struct {
+	author   Author
+	spelling map[string]int
+	order    []string
+	repos    []*scanner.Addon
+}
+
+// BuildAuthorIndex groups the addons by author, merging names that only differ in case or whitespace.
+// The GitHub login of an author is the owner of one of their repos with a matching name, or, if fetchContributors is set,
+// a matching contributor of one of their repos
+func BuildAuthorIndex(addons []*scanner.Addon, fetchContributors bool, generatedAt string) *AuthorIndex {
+	entries := make(map[string]*authorEntry)
+
+	for _, addon := range addons {
+		names := addon.Authors
+		if len(names) == 0 {
+			names = []string{addon.Repo.Owner}
+		}
+
+		seen := make(map[string]bool)
+		for _, name := range names {
+			name = strings.Join(strings.Fields(name), " ")
+			key := strings.ToLower(name)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			entry, ok := entries[key]
+			if !ok {
+				entry = &authorEntry{spelling: make(map[string]int)}
+				entries[key] = entry
+			}
+
+			if entry.spelling[name] == 0 {
+				entry.order = append(entry.order, name)
+			}
+			entry.spelling[name]++
+			entry.repos = append(entry.repos, addon)
+			entry.author.Addons = append(entry.author.Addons, addon.Repo.Id)
+			entry.author.Stars += addon.Repo.Stars
+			entry.author.Downloads += addon.Repo.Downloads
+		}
+	}
+
+	contributors := make(map[string][]string)
+	index := &AuthorIndex{
+		GeneratedAt: generatedAt,
+		Authors:     make([]Author, 0, len(entries)),
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(entries)) {
+		entry := entries[key]
+		author := entry.author
+
+		// the most used spelling, the first one seen on a tie
+		author.Name = entry.order[0]
+		for _, name := range entry.order {
+			if entry.spelling[name] > entry.spelling[author.Name] {
+				author.Name = name
+			}
+		}
+		author.Aliases = []string{}
+		for _, name := range entry.order {
+			if name != author.Name {
+				author.Aliases = append(author.Aliases, name)
+			}
+		}
+
+		author.Login, author.LoginSource = resolveLogin(key, entry.repos, fetchContributors, contributors)
+		if author.Login != "" {
+			author.AvatarURL = fmt.Sprintf("https://github.com/%s.png", author.Login)
+		}
+
+		index.Authors = append(index.Authors, author)
+	}
+
+	return index
+}
+
+func resolveLogin(name string, repos []*scanner.Addon, fetchContributors bool, contributors map[string][]string) (string, string) {
+	if loginKey(name) == "" {
+		return "", ""
+	}
+
+	for _, addon := range repos {
+		if loginKey(addon.Repo.Owner) == loginKey(name) {
+			return addon.Repo.Owner, LoginSourceOwner
+		}
+	}
+
+	if !fetchContributors {
+		return "", ""
+	}
+
+	for _, addon := range repos {
+		logins, ok := contributors[addon.Repo.Id]
+		if !ok {
+			logins = fetchContributorLogins(addon.Repo.Id)
+			contributors[addon.Repo.Id] = logins
+		}
+
+		for _, login := range logins {
+			if loginKey(login) == loginKey(name) {
+				return login, LoginSourceContributor
+			}
+		}
+	}
+
+	return "", ""
+}
+
+// returns the logins of the contributors of a repo, most contributions first and bots excluded,
+// or those fetched until a page failed
+func fetchContributorLogins(repo string) []string {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/contributors?per_page=100&page=", repo)
+
+	var logins []string
+	for page := 1; page <= maxContributorPages; page++ {
+		bytes, err := scanner.MakeGetRequest(fmt.Sprintf("%s%d", url, page))
+		if err != nil {
+			slog.Warn("Failed to fetch contributors", "repo", repo, "stage", "authors", "page", page, "error", err)
+			break
+		}
+
+		var contributors []contributor
+		if err := json.Unmarshal(bytes, &contributors); err != nil {
+			slog.Warn("Failed to parse contributors", "repo", repo, "stage", "authors", "page", page, "error", err)
+			break
+		}
+
+		for _, contributor := range contributors {
+			if contributor.Type != "Bot" {
+				logins = append(logins, contributor.Login)
+			}
+		}
+
+		if len(contributors) < 100 {
+			break
+		}
+	}
+
+	return logins
+}
+
+// keeps only the lowercase letters and digits, so "Meteor Development" matches the login MeteorDevelopment
+func loginKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+func SaveAuthorIndex(path string, index *AuthorIndex, pretty bool) error {
+	data, err := marshal(index, pretty)
+	if err != nil {
+		return fmt.Errorf("Failed to convert author index to JSON: %v", err)
+	}
+
+	if err := WriteFile(path, data); err != nil {
+		return fmt.Errorf("Failed to write author index: %v", err)
+	}
+
+	return nil
+}
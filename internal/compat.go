@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"cmp"
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"fmt"
+	"maps"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+)
+
+// where an addon is known to support a Minecraft version from
+const (
+	CompatSourceGradle       = "gradle"
+	CompatSourceCustom       = "custom"
+	CompatSourceReleaseAsset = "release_asset"
+)
+
+// Compatibility maps every Minecraft version to the addons that support it, newest version first
+type Compatibility struct {
+	GeneratedAt string                 `json:"generated_at"`
+	Versions    []CompatibilityVersion `json:"versions"`
+}
+
+type CompatibilityVersion struct {
+	Version string               `json:"version"`
+	Addons  []CompatibilityAddon `json:"addons"`
+}
+
+type CompatibilityAddon struct {
+	Id       string `json:"id"`
+	Name     string `json:"name"`
+	Verified bool   `json:"verified"`
+	// gradle, custom and/or release_asset
+	Sources []string `json:"sources"`
+	// release asset built for the version, empty if none of the downloads names it
+	Download string `json:"download"`
+	// whether the download is from a prerelease, only when the stable release has none for the version
+	Prerelease bool `json:"prerelease"`
+}
+
+// BuildCompatibility collects the Minecraft versions each addon supports from the version in its gradle files,
+// the supported versions in its meteor-addon-list.json and the versions in the file names of its release assets
+func BuildCompatibility(addons []*scanner.Addon, generatedAt string) *Compatibility {
+	versions := make(map[string][]CompatibilityAddon)
+
+	for _, addon := range addons {
+		sources := make(map[string][]string)
+		downloads := make(map[string]string)
+
+		add := func(version string, source string) {
+			if !scanner.IsMinecraftVersion(version) || slices.Contains(sources[version], source) {
+				return
+			}
+			sources[version] = append(sources[version], source)
+		}
+
+		add(addon.McVersion, CompatSourceGradle)
+		for _, version := range addon.Custom.SupportedVersions {
+			add(strings.TrimSpace(version), CompatSourceCustom)
+		}
+		for _, download := range addon.Links.Downloads {
+			version := scanner.ExtractMCVersionFromFilename(assetName(download))
+			add(version, CompatSourceReleaseAsset)
+
+			// a stable release asset replaces a prerelease one
+			if version == "" {
+				continue
+			}
+			current, ok := downloads[version]
+			if !ok || (isPrerelease(addon, current) && !isPrerelease(addon, download)) {
+				downloads[version] = download
+			}
+		}
+
+		for version, versionSources := range sources {
+			download, ok := downloads[version]
+			versions[version] = append(versions[version], CompatibilityAddon{
+				Id:         addon.Repo.Id,
+				Name:       addon.Name,
+				Verified:   addon.Verified,
+				Sources:    versionSources,
+				Download:   download,
+				Prerelease: ok && isPrerelease(addon, download),
+			})
+		}
+	}
+
+	compatibility := &Compatibility{
+		GeneratedAt: generatedAt,
+		Versions:    make([]CompatibilityVersion, 0, len(versions)),
+	}
+
+	sorted := slices.SortedFunc(maps.Keys(versions), func(a string, b string) int { return scanner.CompareMinecraftVersions(b, a) })
+	for _, version := range sorted {
+		addons := versions[version]
+		slices.SortFunc(addons, func(a CompatibilityAddon, b CompatibilityAddon) int {
+			return cmp.Compare(strings.ToLower(a.Id), strings.ToLower(b.Id))
+		})
+
+		compatibility.Versions = append(compatibility.Versions, CompatibilityVersion{version, addons})
+	}
+
+	return compatibility
+}
+
+func isPrerelease(addon *scanner.Addon, download string) bool {
+	return slices.Contains(addon.Links.Prereleases, download)
+}
+
+// returns the file name at the end of a download url
+func assetName(download string) string {
+	parsed, err := url.Parse(download)
+	if err != nil {
+		return ""
+	}
+
+	return path.Base(parsed.Path)
+}
+
+func SaveCompatibility(path string, compatibility *Compatibility, pretty bool) error {
+	data, err := marshal(compatibility, pretty)
+	if err != nil {
+		return fmt.Errorf("Failed to convert compatibility matrix to JSON: %v", err)
+	}
+
+	if err := WriteFile(path, data); err != nil {
+		return fmt.Errorf("Failed to write compatibility matrix: %v", err)
+	}
+
+	return nil
+}
@@ -4,9 +4,11 @@ import (
 	"dev/cqb13/meteor-addon-scanner/scanner"
 	"encoding/json"
 	"fmt"
-	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 )
 
@@ -42,21 +44,272 @@ func ValidateConfigPath(path string) error {
 	return nil
 }
 
-func LoadInvalidRepoLog(path string, invalidRepoLog map[string]any) bool {
-	file, err := os.Open(path)
+// LoadRetryLog reads the retry log from path, a missing file results in an empty log
+func LoadRetryLog(path string) (*scanner.RetryLog, error) {
+	retryLog := scanner.NewRetryLog()
+
+	bytes, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return retryLog, nil
+	}
 	if err != nil {
-		return false
+		return nil, fmt.Errorf("Failed to read retry log: %v", err)
+	}
+
+	if len(strings.TrimSpace(string(bytes))) == 0 {
+		return retryLog, nil
+	}
+
+	if err := json.Unmarshal(bytes, retryLog); err != nil {
+		return nil, fmt.Errorf("Failed to parse retry log: %v", err)
+	}
+
+	return retryLog, nil
+}
+
+func SaveRetryLog(path string, retryLog *scanner.RetryLog) error {
+	bytes, err := json.MarshalIndent(retryLog, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to convert retry log to JSON: %v", err)
+	}
+
+	if err := WriteFile(path, bytes); err != nil {
+		return fmt.Errorf("Failed to write retry log: %v", err)
+	}
+
+	return nil
+}
+
+// LoadState reads the scan state from path, a missing file results in an empty state
+func LoadState(path string) (*scanner.State, error) {
+	state := scanner.NewState()
+
+	bytes, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read scan state: %v", err)
+	}
+
+	if err := json.Unmarshal(bytes, state); err != nil {
+		return nil, fmt.Errorf("Failed to parse scan state: %v", err)
+	}
+
+	return state, nil
+}
+
+func SaveState(path string, state *scanner.State) error {
+	bytes, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("Failed to convert scan state to JSON: %v", err)
+	}
+
+	if err := WriteFile(path, bytes); err != nil {
+		return fmt.Errorf("Failed to write scan state: %v", err)
+	}
+
+	return nil
+}
+
+func LoadCheckpoint(path string) (*scanner.Checkpoint, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read checkpoint: %v", err)
+	}
+
+	var checkpoint scanner.Checkpoint
+	if err := json.Unmarshal(bytes, &checkpoint); err != nil {
+		return nil, fmt.Errorf("Failed to parse checkpoint: %v", err)
+	}
+
+	return &checkpoint, nil
+}
+
+func SaveCheckpoint(path string, checkpoint *scanner.Checkpoint) error {
+	bytes, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("Failed to convert checkpoint to JSON: %v", err)
+	}
+
+	if err := WriteFile(path, bytes); err != nil {
+		return fmt.Errorf("Failed to write checkpoint: %v", err)
+	}
+
+	return nil
+}
+
+// LoadRepoList reads a list of repos written by SaveRepoList
+func LoadRepoList(path string) (map[string]struct{}, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read repo list: %v", err)
+	}
+
+	var list []string
+	if err := json.Unmarshal(bytes, &list); err != nil {
+		return nil, fmt.Errorf("Failed to parse repo list: %v", err)
+	}
+
+	repos := make(map[string]struct{}, len(list))
+	for _, repo := range list {
+		repos[repo] = struct{}{}
 	}
-	defer file.Close()
 
-	bytes, err := io.ReadAll(file)
+	return repos, nil
+}
+
+// SaveRepoList writes the repos as a sorted json array
+func SaveRepoList(path string, repos map[string]struct{}) error {
+	list := make([]string, 0, len(repos))
+	for repo := range repos {
+		list = append(list, repo)
+	}
+	sort.Strings(list)
+
+	bytes, err := json.MarshalIndent(list, "", "  ")
 	if err != nil {
-		return false
+		return fmt.Errorf("Failed to convert repo list to JSON: %v", err)
+	}
+
+	if err := WriteFile(path, bytes); err != nil {
+		return fmt.Errorf("Failed to write repo list: %v", err)
+	}
+
+	return nil
+}
+
+// CheckConfig lints the config at path and returns every problem found
+func CheckConfig(path string) ([]string, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open config file: %v", err)
+	}
+
+	var problems []string
+
+	// unknown fields are ignored when scanning, so they are most likely typos
+	var config scanner.Config
+	decoder := json.NewDecoder(strings.NewReader(string(bytes)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		if !strings.Contains(err.Error(), "unknown field") {
+			return nil, fmt.Errorf("Failed to parse config file: %v", err)
+		}
+
+		problems = append(problems, err.Error())
+		if err := json.Unmarshal(bytes, &config); err != nil {
+			return nil, fmt.Errorf("Failed to parse config file: %v", err)
+		}
+	}
+
+	problems = append(problems, findDuplicates("repo-blacklist", config.BlacklistedRepos)...)
+	problems = append(problems, findDuplicates("developer-blacklist", config.BlacklistedDevs)...)
+	problems = append(problems, findDuplicates("verified_addons.verified", config.VerifiedAddons.Verified)...)
+
+	for _, repo := range append(config.BlacklistedRepos, config.VerifiedAddons.Verified...) {
+		if strings.Count(repo, "/") != 1 {
+			problems = append(problems, fmt.Sprintf("'%s' is not in the form owner/repo", repo))
+		}
+	}
+
+	repoBlacklist := make(map[string]struct{})
+	for _, repo := range config.BlacklistedRepos {
+		repoBlacklist[strings.ToLower(repo)] = struct{}{}
+	}
+
+	devBlacklist := make(map[string]struct{})
+	for _, dev := range config.BlacklistedDevs {
+		devBlacklist[strings.ToLower(dev)] = struct{}{}
+	}
+
+	for _, repo := range config.VerifiedAddons.Verified {
+		if _, ok := repoBlacklist[strings.ToLower(repo)]; ok {
+			problems = append(problems, fmt.Sprintf("Verified addon '%s' is blacklisted", repo))
+		}
+
+		owner, _, _ := strings.Cut(repo, "/")
+		if _, ok := devBlacklist[strings.ToLower(owner)]; ok {
+			problems = append(problems, fmt.Sprintf("Verified addon '%s' belongs to a blacklisted developer", repo))
+		}
 	}
 
-	for line := range strings.SplitSeq(string(bytes), "\n") {
-		invalidRepoLog[line] = nil
+	if version := config.VerifiedAddons.MinMinecraftVersion; version != "" && !scanner.IsMinecraftVersion(version) {
+		problems = append(problems, fmt.Sprintf("verified_addons.minimum_mc_version '%s' is not a Minecraft version", version))
+	}
+
+	if config.ModuleDescriptions.MinStarCount < 0 {
+		problems = append(problems, "module_descriptions.minimum_star_count must not be negative")
+	}
+
+	// a trigger of 0 flags every addon as suspicious
+	triggers := map[string]int{
+		"suspicion_triggers.name_len":           config.SuspicionTriggers.NameLength,
+		"suspicion_triggers.description_len":    config.SuspicionTriggers.DescriptionLength,
+		"suspicion_triggers.feature_count":      config.SuspicionTriggers.FeatureCount,
+		"suspicion_triggers.supported_versions": config.SuspicionTriggers.SupportedVersions,
+	}
+	for _, name := range slices.Sorted(maps.Keys(triggers)) {
+		if triggers[name] <= 0 {
+			problems = append(problems, fmt.Sprintf("%s must be greater than 0, otherwise every addon is suspicious", name))
+		}
+	}
+
+	for _, host := range config.AllowedImageHosts {
+		if strings.Contains(host, "/") || strings.Contains(host, ":") {
+			problems = append(problems, fmt.Sprintf("allowed_image_hosts entry '%s' must be a bare host name", host))
+		}
+	}
+
+	if config.StaleRetentionDays < 0 {
+		problems = append(problems, "stale_retention_days must not be negative")
+	}
+
+	if config.History.Points < 0 {
+		problems = append(problems, "history.points must not be negative")
+	}
+
+	if config.History.RetentionDays < 0 {
+		problems = append(problems, "history.retention_days must not be negative")
+	}
+
+	if config.Feeds.MaxEntries < 0 {
+		problems = append(problems, "feeds.max_entries must not be negative")
+	}
+
+	if config.Feeds.BaseURL != "" && !strings.HasPrefix(config.Feeds.BaseURL, "https://") {
+		problems = append(problems, fmt.Sprintf("feeds.base_url '%s' must start with https://", config.Feeds.BaseURL))
+	}
+
+	if config.Output.Sort != "" && !slices.Contains(SortOrders, config.Output.Sort) {
+		problems = append(problems, fmt.Sprintf("output.sort '%s' must be one of %s", config.Output.Sort, strings.Join(SortOrders, ", ")))
+	}
+
+	for tag := range config.TagInference.Keywords {
+		if _, ok := scanner.ParseTag(tag); !ok {
+			problems = append(problems, fmt.Sprintf("tag_inference.keywords has unknown tag '%s'", tag))
+		}
+	}
+
+	if config.TagInference.MinConfidence < 0 || config.TagInference.MinConfidence > 1 {
+		problems = append(problems, "tag_inference.min_confidence must be between 0 and 1")
+	}
+
+	return problems, nil
+}
+
+// returns a problem for every entry of list that appears more than once, ignoring case
+func findDuplicates(name string, list []string) []string {
+	var problems []string
+
+	seen := make(map[string]bool)
+	for _, entry := range list {
+		lower := strings.ToLower(entry)
+		if seen[lower] {
+			problems = append(problems, fmt.Sprintf("'%s' is listed more than once in %s", entry, name))
+		}
+		seen[lower] = true
 	}
 
-	return true
+	return problems
 }
@@ -0,0 +1,257 @@
+package internal
+
+import (
+	"cmp"
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Changes is what changed between two scans
+type Changes struct {
+	GeneratedAt  string               `json:"generated_at"`
+	Added        []AddedAddon         `json:"added"`
+	Removed      []RemovedAddon       `json:"removed"`
+	Verification []VerificationChange `json:"verification"`
+	McVersions   []McVersionChange    `json:"mc_versions"`
+	Releases     []ReleaseChange      `json:"releases"`
+	Features     []FeatureChange      `json:"features"`
+}
+
+type AddedAddon struct {
+	Id       string `json:"id"`
+	Name     string `json:"name"`
+	Verified bool   `json:"verified"`
+}
+
+type RemovedAddon struct {
+	Id     string `json:"id"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+type VerificationChange struct {
+	Id       string `json:"id"`
+	Name     string `json:"name"`
+	Verified bool   `json:"verified"`
+}
+
+type McVersionChange struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+type ReleaseChange struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Old       string `json:"old"`
+	New       string `json:"new"`
+	McVersion string `json:"mc_version"`
+}
+
+// FeatureChange lists the features of one kind, such as modules, that changed in an addon
+type FeatureChange struct {
+	Id      string          `json:"id"`
+	Name    string          `json:"name"`
+	Kind    string          `json:"kind"`
+	Added   []string        `json:"added"`
+	Removed []string        `json:"removed"`
+	Renamed []FeatureRename `json:"renamed"`
+}
+
+type FeatureRename struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// Count returns the number of changes
+func (c *Changes) Count() int {
+	return len(c.Added) + len(c.Removed) + len(c.Verification) + len(c.McVersions) + len(c.Releases) + len(c.Features)
+}
+
+// DiffAddons compares the addons of two scans. The config and retry log are optional,
+// they are used to explain why an addon was removed
+func DiffAddons(previous []*scanner.Addon, current []*scanner.Addon, config *scanner.Config, retryLog *scanner.RetryLog, now time.Time) *Changes {
+	changes := Changes{
+		GeneratedAt:  now.UTC().Format(time.RFC3339),
+		Added:        []AddedAddon{},
+		Removed:      []RemovedAddon{},
+		Verification: []VerificationChange{},
+		McVersions:   []McVersionChange{},
+		Releases:     []ReleaseChange{},
+		Features:     []FeatureChange{},
+	}
+
+	oldAddons := addonsById(previous)
+	newAddons := addonsById(current)
+
+	for _, id := range slices.Sorted(maps.Keys(newAddons)) {
+		addon := newAddons[id]
+		old, ok := oldAddons[id]
+		if !ok {
+			changes.Added = append(changes.Added, AddedAddon{addon.Repo.Id, addon.Name, addon.Verified})
+			continue
+		}
+
+		if old.Verified != addon.Verified {
+			changes.Verification = append(changes.Verification, VerificationChange{addon.Repo.Id, addon.Name, addon.Verified})
+		}
+
+		if old.McVersion != addon.McVersion {
+			changes.McVersions = append(changes.McVersions, McVersionChange{addon.Repo.Id, addon.Name, old.McVersion, addon.McVersion})
+		}
+
+		if addon.Links.LatestRelease != "" && old.Links.LatestRelease != addon.Links.LatestRelease {
+			changes.Releases = append(changes.Releases, ReleaseChange{addon.Repo.Id, addon.Name, old.Links.LatestRelease, addon.Links.LatestRelease, addon.McVersion})
+		}
+
+		kinds := []struct {
+			name   string
+			before []string
+			after  []string
+		}{
+			{"modules", featureNames(old.Features.Modules), featureNames(addon.Features.Modules)},
+			{"commands", featureNames(old.Features.Commands), featureNames(addon.Features.Commands)},
+			{"hud_elements", featureNames(old.Features.HudElements), featureNames(addon.Features.HudElements)},
+			{"tabs", old.Features.Tabs, addon.Features.Tabs},
+			{"themes", old.Features.Themes, addon.Features.Themes},
+		}
+		for _, kind := range kinds {
+			change := diffFeatures(kind.before, kind.after)
+			if len(change.Added) == 0 && len(change.Removed) == 0 && len(change.Renamed) == 0 {
+				continue
+			}
+
+			change.Id = addon.Repo.Id
+			change.Name = addon.Name
+			change.Kind = kind.name
+			changes.Features = append(changes.Features, change)
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(oldAddons)) {
+		if _, ok := newAddons[id]; ok {
+			continue
+		}
+
+		addon := oldAddons[id]
+		changes.Removed = append(changes.Removed, RemovedAddon{addon.Repo.Id, addon.Name, removalReason(addon, config, retryLog)})
+	}
+
+	return &changes
+}
+
+func addonsById(addons []*scanner.Addon) map[string]*scanner.Addon {
+	byId := make(map[string]*scanner.Addon, len(addons))
+	for _, addon := range addons {
+		byId[strings.ToLower(addon.Repo.Id)] = addon
+	}
+
+	return byId
+}
+
+func featureNames(features []scanner.Feature) []string {
+	names := make([]string, len(features))
+	for i, feature := range features {
+		names[i] = feature.Name
+	}
+
+	return names
+}
+
+// ignores case, spaces, dashes and underscores, so "Auto Totem" and "AutoTotem" are the same feature
+func normalizeFeatureName(name string) string {
+	return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(name))
+}
+
+// diffs two lists of feature names, a removed and an added feature that only differ
+// in case, spacing or punctuation count as a rename
+func diffFeatures(before []string, after []string) FeatureChange {
+	change := FeatureChange{Added: []string{}, Removed: []string{}, Renamed: []FeatureRename{}}
+
+	afterSet := make(map[string]bool, len(after))
+	for _, name := range after {
+		afterSet[name] = true
+	}
+	beforeSet := make(map[string]bool, len(before))
+	for _, name := range before {
+		beforeSet[name] = true
+	}
+
+	// added features by normalized name, to find renames
+	added := make(map[string]string)
+	for _, name := range after {
+		if !beforeSet[name] {
+			added[normalizeFeatureName(name)] = name
+		}
+	}
+
+	for _, name := range before {
+		if afterSet[name] {
+			continue
+		}
+
+		normalized := normalizeFeatureName(name)
+		if renamed, ok := added[normalized]; ok {
+			change.Renamed = append(change.Renamed, FeatureRename{name, renamed})
+			delete(added, normalized)
+			continue
+		}
+
+		change.Removed = append(change.Removed, name)
+	}
+
+	for _, name := range added {
+		change.Added = append(change.Added, name)
+	}
+
+	slices.Sort(change.Added)
+	slices.Sort(change.Removed)
+	slices.SortFunc(change.Renamed, func(a, b FeatureRename) int { return cmp.Compare(a.Old, b.Old) })
+
+	return change
+}
+
+// explains why an addon is missing from the new scan
+func removalReason(addon *scanner.Addon, config *scanner.Config, retryLog *scanner.RetryLog) string {
+	if config != nil {
+		for _, repo := range config.BlacklistedRepos {
+			if strings.EqualFold(repo, addon.Repo.Id) {
+				return "Repo is blacklisted"
+			}
+		}
+
+		for _, dev := range config.BlacklistedDevs {
+			if strings.EqualFold(dev, addon.Repo.Owner) {
+				return "Developer is blacklisted"
+			}
+		}
+	}
+
+	if retryLog != nil {
+		if entry, ok := retryLog.Get(addon.Repo.Id); ok {
+			return fmt.Sprintf("Failed to parse (%s): %s", entry.Class, entry.Reason)
+		}
+	}
+
+	return "No longer found"
+}
+
+func SaveChanges(path string, changes *Changes) error {
+	bytes, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to convert changes to JSON: %v", err)
+	}
+
+	if err := WriteFile(path, bytes); err != nil {
+		return fmt.Errorf("Failed to write changes: %v", err)
+	}
+
+	return nil
+}
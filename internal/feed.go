@@ -0,0 +1,279 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// number of entries kept in a feed when the config does not set one
+const defaultFeedEntries = 50
+
+type AtomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Id      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  AtomAuthor  `xml:"author"`
+	Links   []AtomLink  `xml:"link"`
+	Entries []AtomEntry `xml:"entry"`
+}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type AtomEntry struct {
+	Id         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Links      []AtomLink     `xml:"link"`
+	Summary    string         `xml:"summary,omitempty"`
+	Author     *AtomAuthor    `xml:"author,omitempty"`
+	Categories []AtomCategory `xml:"category"`
+}
+
+type AtomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type AtomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr,omitempty"`
+}
+
+// a feed entry along with what decides which tag and version feeds it belongs to
+type feedEntry struct {
+	entry    AtomEntry
+	kind     string
+	tags     []string
+	versions []string
+}
+
+// UpdateFeeds adds the new addons, releases and newly verified addons in changes to the Atom feeds in dir:
+// new-addons.xml, releases.xml, verified.xml, all.xml and one feed per tag in tags/ and per Minecraft version in versions/.
+// Entries already in a feed are kept, so a feed holds the most recent changes over many scans.
+// history may be nil, it is used to tell when an added addon was first seen
+func UpdateFeeds(dir string, changes *Changes, addons []*scanner.Addon, history History, config *scanner.Config, now time.Time) error {
+	maxEntries := config.Feeds.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultFeedEntries
+	}
+
+	byId := addonsById(addons)
+	updated := now.UTC().Format(time.RFC3339)
+	day := now.UTC().Format(time.DateOnly)
+
+	// link defaults to the GitHub page of the addon
+	var entries []feedEntry
+	add := func(kind string, id string, title string, link string, detail string) {
+		addon, ok := byId[strings.ToLower(id)]
+		if !ok {
+			return
+		}
+
+		if link == "" {
+			link = addon.Links.Github
+		}
+
+		entry := AtomEntry{
+			Id:      entryId(kind, id, detail),
+			Title:   title,
+			Updated: updated,
+			Links:   []AtomLink{{Href: link}},
+			Summary: addonSummary(addon),
+		}
+		if len(addon.Authors) != 0 {
+			entry.Author = &AtomAuthor{strings.Join(addon.Authors, ", ")}
+		}
+
+		tags := addonTags(addon)
+		versions := addonVersions(addon)
+		for _, tag := range addon.Custom.Tags {
+			entry.Categories = append(entry.Categories, AtomCategory{Term: tag})
+		}
+		for _, tag := range inferredTagNames(addon) {
+			entry.Categories = append(entry.Categories, AtomCategory{Term: tag, Label: tag + " (inferred)"})
+		}
+		for _, version := range versions {
+			entry.Categories = append(entry.Categories, AtomCategory{Term: version})
+		}
+
+		entries = append(entries, feedEntry{entry, kind, tags, versions})
+	}
+
+	for _, added := range changes.Added {
+		// the first point in the history is when the addon was first seen, it stays the same however often the
+		// feeds are updated. Without one an addon that is added again on a later day is a new entry
+		firstSeen := day
+		if points := history[added.Id]; len(points) != 0 {
+			firstSeen = points[0].Time.UTC().Format(time.RFC3339)
+		}
+
+		add("added", added.Id, fmt.Sprintf("New addon: %s", added.Name), "", firstSeen)
+	}
+
+	for _, release := range changes.Releases {
+		// the release url is unique, so the same release is never listed twice
+		add("release", release.Id, fmt.Sprintf("New release of %s", release.Name), release.New, release.New)
+	}
+
+	for _, change := range changes.Verification {
+		if change.Verified {
+			add("verified", change.Id, fmt.Sprintf("Now verified: %s", change.Name), "", day)
+		}
+	}
+
+	feeds := map[string]struct {
+		title   string
+		entries []AtomEntry
+	}{
+		"all.xml":        {"All addon changes", nil},
+		"new-addons.xml": {"New addons", nil},
+		"releases.xml":   {"New addon releases", nil},
+		"verified.xml":   {"Newly verified addons", nil},
+	}
+	kindFeeds := map[string]string{"added": "new-addons.xml", "release": "releases.xml", "verified": "verified.xml"}
+
+	addTo := func(name string, title string, entry AtomEntry) {
+		feed := feeds[name]
+		if feed.title == "" {
+			feed.title = title
+		}
+		feed.entries = append(feed.entries, entry)
+		feeds[name] = feed
+	}
+
+	for _, entry := range entries {
+		addTo("all.xml", "", entry.entry)
+		addTo(kindFeeds[entry.kind], "", entry.entry)
+
+		for _, tag := range entry.tags {
+			addTo(path.Join("tags", feedName(tag)+".xml"), fmt.Sprintf("Addon changes tagged %s", tag), entry.entry)
+		}
+		for _, version := range entry.versions {
+			addTo(path.Join("versions", feedName(version)+".xml"), fmt.Sprintf("Addon changes for Minecraft %s", version), entry.entry)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(feeds)) {
+		feed := feeds[name]
+		if err := updateFeed(filepath.Join(dir, filepath.FromSlash(name)), name, feed.title, feed.entries, config.Feeds.BaseURL, updated, maxEntries); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// merges entries into the feed at file, newest first, creating it if needed
+func updateFeed(file string, name string, title string, entries []AtomEntry, baseURL string, updated string, maxEntries int) error {
+	feed := AtomFeed{
+		Id:    feedId(baseURL, name),
+		Title: title,
+	}
+
+	if data, err := os.ReadFile(file); err == nil {
+		if err := xml.Unmarshal(data, &feed); err != nil {
+			return fmt.Errorf("Failed to parse feed %s: %v", name, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("Failed to read feed %s: %v", name, err)
+	}
+
+	// an existing feed without new entries is left untouched
+	if len(entries) == 0 && feed.Updated != "" {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var merged []AtomEntry
+	for _, entry := range append(entries, feed.Entries...) {
+		if seen[entry.Id] {
+			continue
+		}
+		seen[entry.Id] = true
+		merged = append(merged, entry)
+	}
+	if len(merged) > maxEntries {
+		merged = merged[:maxEntries]
+	}
+
+	feed.Title = title
+	feed.Updated = updated
+	feed.Author = AtomAuthor{"Meteor Addon Scanner"}
+	feed.Entries = merged
+	feed.Links = nil
+	if baseURL != "" {
+		feed.Links = []AtomLink{{Href: strings.TrimSuffix(baseURL, "/") + "/" + name, Rel: "self"}}
+	}
+
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to convert feed %s to XML: %v", name, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("Failed to write feed %s: %v", name, err)
+	}
+
+	if err := WriteFile(file, append([]byte(xml.Header), data...)); err != nil {
+		return fmt.Errorf("Failed to write feed %s: %v", name, err)
+	}
+
+	return nil
+}
+
+// entry ids only depend on what the entry is about, so feed readers never show an entry twice
+func entryId(kind string, repo string, detail string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(repo) + "\n" + detail))
+	return fmt.Sprintf("urn:meteor-addons:%s:%s", kind, hex.EncodeToString(sum[:])[:24])
+}
+
+func feedId(baseURL string, name string) string {
+	if baseURL != "" {
+		return strings.TrimSuffix(baseURL, "/") + "/" + name
+	}
+
+	return "urn:meteor-addons:feed:" + name
+}
+
+var feedNameRegex = regexp.MustCompile(`[^a-z0-9.]+`)
+
+// turns a tag or version into a file name
+func feedName(name string) string {
+	return strings.Trim(feedNameRegex.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+func addonSummary(addon *scanner.Addon) string {
+	description := addon.Description
+	if addon.Custom.Description != "" {
+		description = addon.Custom.Description
+	}
+
+	return description
+}
+
+// returns the scanned and custom Minecraft versions of an addon, without duplicates
+func addonVersions(addon *scanner.Addon) []string {
+	var versions []string
+	for _, version := range append([]string{addon.McVersion}, addon.Custom.SupportedVersions...) {
+		if version != "" && !slices.Contains(versions, version) {
+			versions = append(versions, version)
+		}
+	}
+
+	return versions
+}
@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes data to a temp file next to path and renames it into place,
+// so a crash never leaves a truncated file behind. A path of "-" writes to stdout
+func WriteFile(path string, data []byte) error {
+	if path == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := file.Name()
+
+	// removes the temp file if anything fails before the rename
+	renamed := false
+	defer func() {
+		if !renamed {
+			os.Remove(tempPath)
+		}
+	}()
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tempPath, 0644); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tempPath, path); err != nil {
+		return err
+	}
+	renamed = true
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+type HistoryPoint struct {
+	Time         time.Time `json:"time"`
+	Stars        int       `json:"stars"`
+	Forks        int       `json:"forks"`
+	Downloads    int       `json:"downloads"`
+	FeatureCount int       `json:"feature_count"`
+}
+
+type HistoryDelta struct {
+	Stars        int `json:"stars"`
+	Forks        int `json:"forks"`
+	Downloads    int `json:"downloads"`
+	FeatureCount int `json:"feature_count"`
+}
+
+// AddonHistory is the published history of a single addon
+type AddonHistory struct {
+	Points   []HistoryPoint `json:"points"`
+	Delta7d  HistoryDelta   `json:"delta_7d"`
+	Delta30d HistoryDelta   `json:"delta_30d"`
+}
+
+// History holds the metrics of every addon for each scan, oldest first, keyed by repo id
+type History map[string][]HistoryPoint
+
+// LoadHistory reads the history store from path, a missing file results in an empty history
+func LoadHistory(path string) (History, error) {
+	history := make(History)
+
+	bytes, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read history: %v", err)
+	}
+
+	if err := json.Unmarshal(bytes, &history); err != nil {
+		return nil, fmt.Errorf("Failed to parse history: %v", err)
+	}
+
+	return history, nil
+}
+
+func SaveHistory(path string, history History) error {
+	bytes, err := json.Marshal(history)
+	if err != nil {
+		return fmt.Errorf("Failed to convert history to JSON: %v", err)
+	}
+
+	if err := WriteFile(path, bytes); err != nil {
+		return fmt.Errorf("Failed to write history: %v", err)
+	}
+
+	return nil
+}
+
+// Record appends the current metrics of the addons and drops points older than retentionDays.
+// Stale addons are skipped since their metrics were not refreshed by this scan
+func (h History) Record(addons []*scanner.Addon, retentionDays int, now time.Time) {
+	for _, addon := range addons {
+		if addon.Stale {
+			continue
+		}
+
+		h[addon.Repo.Id] = append(h[addon.Repo.Id], HistoryPoint{
+			Time:         now.UTC(),
+			Stars:        addon.Repo.Stars,
+			Forks:        addon.Repo.Forks,
+			Downloads:    addon.Repo.Downloads,
+			FeatureCount: addon.Features.FeatureCount,
+		})
+	}
+
+	if retentionDays <= 0 {
+		return
+	}
+
+	cutoff := now.AddDate(0, 0, -retentionDays)
+	for repo, points := range h {
+		kept := points[:0]
+		for _, point := range points {
+			if !point.Time.Before(cutoff) {
+				kept = append(kept, point)
+			}
+		}
+
+		if len(kept) == 0 {
+			delete(h, repo)
+		} else {
+			h[repo] = kept
+		}
+	}
+}
+
+// Delta returns how much the metrics of the repo changed over the given number of days.
+// If the history is shorter than that, the change since the oldest point is returned
+func (h History) Delta(repo string, days int, now time.Time) HistoryDelta {
+	points := h[repo]
+	if len(points) == 0 {
+		return HistoryDelta{}
+	}
+
+	latest := points[len(points)-1]
+	cutoff := now.AddDate(0, 0, -days)
+
+	// newest point at or before the cutoff
+	base := points[0]
+	for _, point := range points {
+		if point.Time.After(cutoff) {
+			break
+		}
+		base = point
+	}
+
+	return HistoryDelta{
+		Stars:        latest.Stars - base.Stars,
+		Forks:        latest.Forks - base.Forks,
+		Downloads:    latest.Downloads - base.Downloads,
+		FeatureCount: latest.FeatureCount - base.FeatureCount,
+	}
+}
+
+// Summarize returns the last maxPoints points and the 7 and 30 day deltas of every addon
+func (h History) Summarize(addons []*scanner.Addon, maxPoints int, now time.Time) map[string]AddonHistory {
+	summary := make(map[string]AddonHistory, len(addons))
+
+	for _, addon := range addons {
+		points := h[addon.Repo.Id]
+		if maxPoints > 0 && len(points) > maxPoints {
+			points = points[len(points)-maxPoints:]
+		}
+
+		summary[addon.Repo.Id] = AddonHistory{
+			Points:   points,
+			Delta7d:  h.Delta(addon.Repo.Id, 7, now),
+			Delta30d: h.Delta(addon.Repo.Id, 30, now),
+		}
+	}
+
+	return summary
+}
+
+func SaveHistorySummary(path string, summary map[string]AddonHistory) error {
+	bytes, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("Failed to convert history summary to JSON: %v", err)
+	}
+
+	if err := WriteFile(path, bytes); err != nil {
+		return fmt.Errorf("Failed to write history summary: %v", err)
+	}
+
+	return nil
+}
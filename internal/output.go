@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// SchemaVersion is bumped whenever a field of the output is removed or changes meaning
+const SchemaVersion = 1
+
+// Output wraps the addons with what is needed to tell which scan and scanner produced them
+type Output struct {
+	SchemaVersion  int              `json:"schema_version"`
+	GeneratedAt    string           `json:"generated_at"`
+	ScannerVersion string           `json:"scanner_version"`
+	ConfigHash     string           `json:"config_hash"`
+	Summary        OutputSummary    `json:"summary"`
+	Addons         []*scanner.Addon `json:"addons"`
+}
+
+type OutputSummary struct {
+	Addons   int `json:"addons"`
+	Verified int `json:"verified"`
+	Archived int `json:"archived"`
+	Forks    int `json:"forks"`
+	Stale    int `json:"stale"`
+}
+
+func NewOutput(addons []*scanner.Addon, config *scanner.Config, scannerVersion string, now time.Time) (*Output, error) {
+	hash, err := ConfigHash(config)
+	if err != nil {
+		return nil, err
+	}
+
+	output := Output{
+		SchemaVersion:  SchemaVersion,
+		GeneratedAt:    now.UTC().Format(time.RFC3339),
+		ScannerVersion: scannerVersion,
+		ConfigHash:     hash,
+		Addons:         addons,
+	}
+
+	if output.Addons == nil {
+		output.Addons = []*scanner.Addon{}
+	}
+
+	output.Summary = summarize(addons)
+
+	return &output, nil
+}
+
+func summarize(addons []*scanner.Addon) OutputSummary {
+	summary := OutputSummary{Addons: len(addons)}
+	for _, addon := range addons {
+		if addon.Verified {
+			summary.Verified++
+		}
+		if addon.Repo.Archived {
+			summary.Archived++
+		}
+		if addon.Repo.Fork {
+			summary.Forks++
+		}
+		if addon.Stale {
+			summary.Stale++
+		}
+	}
+
+	return summary
+}
+
+// ConfigHash returns the sha256 of the config, so outputs made with different configs can be told apart
+func ConfigHash(config *scanner.Config) (string, error) {
+	bytes, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("Failed to convert config to JSON: %v", err)
+	}
+
+	sum := sha256.Sum256(bytes)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// LoadAddons reads the addons written by a previous scan, either wrapped in an output or as a bare array
+func LoadAddons(path string) ([]*scanner.Addon, error) {
+	output, err := LoadOutput(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return output.Addons, nil
+}
+
+// LoadOutput reads the output written by a previous scan, keeping when and by what it was generated.
+// A bare array of addons is wrapped in an output without any of that
+func LoadOutput(path string) (*Output, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read addons file: %v", err)
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) != 0 && trimmed[0] == '[' {
+		var addons []*scanner.Addon
+		if err := json.Unmarshal(data, &addons); err != nil {
+			return nil, fmt.Errorf("Failed to parse addons file: %v", err)
+		}
+
+		if addons == nil {
+			addons = []*scanner.Addon{}
+		}
+
+		return &Output{SchemaVersion: SchemaVersion, Summary: summarize(addons), Addons: addons}, nil
+	}
+
+	var output Output
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("Failed to parse addons file: %v", err)
+	}
+
+	if output.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("Addons file has schema version %d, this scanner only understands up to %d", output.SchemaVersion, SchemaVersion)
+	}
+
+	if output.Addons == nil {
+		output.Addons = []*scanner.Addon{}
+	}
+
+	return &output, nil
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Schema returns a JSON Schema describing how v is encoded, generated from its Go type.
+// Named struct types are placed in $defs and referenced, so the schema follows the Go types
+func Schema(v any, title string) map[string]any {
+	defs := make(map[string]any)
+
+	schema := map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title":   title,
+	}
+	for key, value := range typeSchema(reflect.TypeOf(v), defs) {
+		schema[key] = value
+	}
+	schema["$defs"] = defs
+
+	return schema
+}
+
+func typeSchema(t reflect.Type, defs map[string]any) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		// nil pointers are encoded as null
+		return map[string]any{"anyOf": []any{typeSchema(t.Elem(), defs), map[string]any{"type": "null"}}}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		// nil slices are encoded as null
+		return map[string]any{"type": []string{"array", "null"}, "items": typeSchema(t.Elem(), defs)}
+	case reflect.Map:
+		return map[string]any{"type": []string{"object", "null"}, "additionalProperties": typeSchema(t.Elem(), defs)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, defs)
+		}
+
+		if _, ok := defs[t.Name()]; !ok {
+			// reserves the name first, so recursive types terminate
+			defs[t.Name()] = nil
+			defs[t.Name()] = structSchema(t, defs)
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	default:
+		return map[string]any{}
+	}
+}
+
+func structSchema(t reflect.Type, defs map[string]any) map[string]any {
+	properties := make(map[string]any)
+	required := []string{}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = typeSchema(field.Type, defs)
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
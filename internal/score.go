@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"math"
+	"time"
+)
+
+// growth older than the window is ignored, growth within it loses half its weight every half life
+const trendWindowDays = 30
+const trendHalfLife = 7 * 24 * time.Hour
+
+// a star is worth as much trending score as this many downloads
+const downloadsPerStar = 10
+
+// maximum points each quality component can contribute, they add up to 100
+const (
+	verifiedPoints     = 25
+	recentUpdatePoints = 25
+	releasePoints      = 25
+	descriptionPoints  = 25
+)
+
+// updates within this many days get full points, after that they decay linearly to 0 at a year
+const recentUpdateDays = 30
+
+// ScoreAddons computes the trending and quality score of every addon, history may be nil
+func ScoreAddons(addons []*scanner.Addon, history History, now time.Time) {
+	for _, addon := range addons {
+		var breakdown scanner.ScoreBreakdown
+
+		breakdown.StarGrowth, breakdown.DownloadGrowth = weightedGrowth(history[addon.Repo.Id], now)
+
+		if addon.Verified {
+			breakdown.Verified = verifiedPoints
+		}
+
+		breakdown.RecentUpdate = recentUpdateScore(addon.Repo.LastUpdate, now)
+
+		if addon.Links.LatestRelease != "" {
+			breakdown.Release = releasePoints
+		}
+
+		breakdown.Descriptions = descriptionScore(addon.Features)
+
+		addon.Score = &scanner.Score{
+			Trending:  round(breakdown.StarGrowth + breakdown.DownloadGrowth/downloadsPerStar),
+			Quality:   round(breakdown.Verified + breakdown.RecentUpdate + breakdown.Release + breakdown.Descriptions),
+			Breakdown: roundBreakdown(breakdown),
+		}
+	}
+}
+
+// sums the star and download growth between consecutive points within the trend window,
+// weighting each step by how long ago it happened
+func weightedGrowth(points []HistoryPoint, now time.Time) (float64, float64) {
+	cutoff := now.AddDate(0, 0, -trendWindowDays)
+
+	var stars, downloads float64
+	for i := 1; i < len(points); i++ {
+		if points[i].Time.Before(cutoff) {
+			continue
+		}
+
+		age := now.Sub(points[i].Time)
+		weight := math.Pow(0.5, float64(age)/float64(trendHalfLife))
+
+		stars += weight * float64(points[i].Stars-points[i-1].Stars)
+		downloads += weight * float64(points[i].Downloads-points[i-1].Downloads)
+	}
+
+	return stars, downloads
+}
+
+func recentUpdateScore(lastUpdate string, now time.Time) float64 {
+	updated, err := time.Parse(time.RFC3339, lastUpdate)
+	if err != nil {
+		return 0
+	}
+
+	days := now.Sub(updated).Hours() / 24
+	if days <= recentUpdateDays {
+		return recentUpdatePoints
+	}
+
+	if days >= 365 {
+		return 0
+	}
+
+	return recentUpdatePoints * (365 - days) / (365 - recentUpdateDays)
+}
+
+// scores the share of modules, commands and hud elements that have a description
+func descriptionScore(features scanner.Features) float64 {
+	total := 0
+	described := 0
+
+	for _, list := range [][]scanner.Feature{features.Modules, features.Commands, features.HudElements} {
+		for _, feature := range list {
+			total++
+			if feature.Description != "" {
+				described++
+			}
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	return descriptionPoints * float64(described) / float64(total)
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+func roundBreakdown(breakdown scanner.ScoreBreakdown) scanner.ScoreBreakdown {
+	return scanner.ScoreBreakdown{
+		StarGrowth:     round(breakdown.StarGrowth),
+		DownloadGrowth: round(breakdown.DownloadGrowth),
+		Verified:       round(breakdown.Verified),
+		RecentUpdate:   round(breakdown.RecentUpdate),
+		Release:        round(breakdown.Release),
+		Descriptions:   round(breakdown.Descriptions),
+	}
+}
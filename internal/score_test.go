@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"testing"
+	"time"
+)
+
+func TestQualityPointsAddUpTo100(t *testing.T) {
+	if total := verifiedPoints + recentUpdatePoints + releasePoints + descriptionPoints; total != 100 {
+		t.Fatalf("quality points add up to %d, expected 100", total)
+	}
+}
+
+func TestRecentlyPushedAddonScoresHigher(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	newAddon := func(lastUpdate time.Time) *scanner.Addon {
+		addon := &scanner.Addon{Verified: true}
+		addon.Repo.Id = "owner/repo"
+		addon.Repo.LastUpdate = lastUpdate.Format(time.RFC3339)
+		addon.Links.LatestRelease = "https://github.com/owner/repo/releases/download/v1/addon.jar"
+		addon.Features.Modules = []scanner.Feature{{Name: "Module", Description: "Does something"}}
+		return addon
+	}
+
+	recent := newAddon(now.AddDate(0, 0, -3))
+	stale := newAddon(now.AddDate(-2, 0, 0))
+	ScoreAddons([]*scanner.Addon{recent, stale}, nil, now)
+
+	if recent.Score.Breakdown.RecentUpdate != recentUpdatePoints {
+		t.Errorf("recent update of a repo pushed 3 days ago is %v, expected %v", recent.Score.Breakdown.RecentUpdate, float64(recentUpdatePoints))
+	}
+	if stale.Score.Breakdown.RecentUpdate != 0 {
+		t.Errorf("recent update of a repo pushed 2 years ago is %v, expected 0", stale.Score.Breakdown.RecentUpdate)
+	}
+	if recent.Score.Quality <= stale.Score.Quality {
+		t.Errorf("recently pushed addon scored %v, not higher than the stale one with %v", recent.Score.Quality, stale.Score.Quality)
+	}
+}
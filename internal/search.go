@@ -0,0 +1,203 @@
+package internal
+
+import (
+	"cmp"
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const SearchIndexVersion = 1
+
+// how much an occurrence of a term counts in each field
+var SearchFieldWeights = map[string]int{
+	"name":                5,
+	"description":         2,
+	"custom_description":  2,
+	"feature_name":        3,
+	"feature_description": 1,
+}
+
+// words too common to be worth indexing
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true, "for": true,
+	"from": true, "in": true, "is": true, "it": true, "of": true, "on": true, "or": true, "that": true, "the": true,
+	"this": true, "to": true, "with": true, "you": true, "your": true,
+}
+
+// SearchIndex is a prebuilt full-text index of addons, small enough to be loaded by a client-side search.
+// Terms are tokenized with Tokenize and stemmed with Stem, queries have to be processed the same way
+type SearchIndex struct {
+	Version int            `json:"version"`
+	Fields  map[string]int `json:"fields"`
+	// id and name of every addon, terms refer to addons by their position in this list
+	Docs [][2]string `json:"docs"`
+	// every term maps to a flat list of addon position and weighted term frequency pairs
+	Terms map[string][]int `json:"terms"`
+}
+
+type SearchHit struct {
+	Id    string  `json:"id"`
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+// BuildSearchIndex indexes the names and descriptions of the addons and of their modules, commands and HUD elements
+func BuildSearchIndex(addons []*scanner.Addon) *SearchIndex {
+	index := &SearchIndex{
+		Version: SearchIndexVersion,
+		Fields:  SearchFieldWeights,
+		Docs:    make([][2]string, 0, len(addons)),
+		Terms:   make(map[string][]int),
+	}
+
+	for position, addon := range addons {
+		index.Docs = append(index.Docs, [2]string{addon.Repo.Id, addon.Name})
+
+		frequencies := make(map[string]int)
+		add := func(field string, text string) {
+			for _, term := range searchTerms(text) {
+				frequencies[term] += SearchFieldWeights[field]
+			}
+		}
+
+		add("name", addon.Name)
+		add("description", addon.Description)
+		add("custom_description", addon.Custom.Description)
+		for _, features := range [][]scanner.Feature{addon.Features.Modules, addon.Features.Commands, addon.Features.HudElements} {
+			for _, feature := range features {
+				add("feature_name", feature.Name)
+				add("feature_description", feature.Description)
+			}
+		}
+
+		for _, term := range slices.Sorted(maps.Keys(frequencies)) {
+			index.Terms[term] = append(index.Terms[term], position, frequencies[term])
+		}
+	}
+
+	return index
+}
+
+// Query returns up to limit addons matching any of the words in query, best match first.
+// Each matched term adds its weighted frequency times how rare the term is to the score
+func (index *SearchIndex) Query(query string, limit int) []SearchHit {
+	scores := make(map[int]float64)
+	for _, term := range searchTerms(query) {
+		postings := index.Terms[term]
+		if len(postings) == 0 {
+			continue
+		}
+
+		idf := math.Log(1 + float64(len(index.Docs))/float64(len(postings)/2))
+		for i := 0; i+1 < len(postings); i += 2 {
+			scores[postings[i]] += float64(postings[i+1]) * idf
+		}
+	}
+
+	hits := make([]SearchHit, 0, len(scores))
+	for position, score := range scores {
+		if position < 0 || position >= len(index.Docs) {
+			continue
+		}
+
+		doc := index.Docs[position]
+		hits = append(hits, SearchHit{Id: doc[0], Name: doc[1], Score: math.Round(score*1000) / 1000})
+	}
+
+	slices.SortFunc(hits, func(a SearchHit, b SearchHit) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), strings.Compare(a.Id, b.Id))
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	return hits
+}
+
+// Tokenize splits text into lowercase words at anything that is not a letter or digit and at camel case boundaries,
+// so "AutoCrystal" and "auto-crystal" both become "auto" and "crystal"
+func Tokenize(text string) []string {
+	var tokens []string
+	var current []rune
+
+	flush := func() {
+		if len(current) != 0 {
+			tokens = append(tokens, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+
+	runes := []rune(text)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+
+		// a new word starts at an upper case letter after a lower case one, or before one in a run of capitals like "HUDElement"
+		if unicode.IsUpper(r) && i > 0 && len(current) != 0 {
+			previous := runes[i-1]
+			if unicode.IsLower(previous) || (unicode.IsUpper(previous) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				flush()
+			}
+		}
+
+		current = append(current, r)
+	}
+	flush()
+
+	return tokens
+}
+
+// tokenizes and stems text, dropping stop words and single characters
+func searchTerms(text string) []string {
+	var terms []string
+	for _, token := range Tokenize(text) {
+		if len([]rune(token)) < 2 || stopWords[token] {
+			continue
+		}
+
+		terms = append(terms, Stem(token))
+	}
+
+	return terms
+}
+
+func LoadSearchIndex(path string) (*SearchIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read search index: %v", err)
+	}
+
+	var index SearchIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("Failed to parse search index: %v", err)
+	}
+
+	if index.Version > SearchIndexVersion {
+		return nil, fmt.Errorf("Search index has version %d, this scanner only understands up to %d", index.Version, SearchIndexVersion)
+	}
+
+	return &index, nil
+}
+
+func SaveSearchIndex(path string, index *SearchIndex) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("Failed to convert search index to JSON: %v", err)
+	}
+
+	if err := WriteFile(path, data); err != nil {
+		return fmt.Errorf("Failed to write search index: %v", err)
+	}
+
+	return nil
+}
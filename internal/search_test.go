@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSiteSearchMatchesSearchTerms(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node is not installed")
+	}
+
+	texts := []string{
+		"AutoCrystal", "auto-crystal", "HUDElement", "ElytraFly v2", "Crystals, crystallized and crystallizing",
+		"caresses ponies ties caress cats", "feed agreed plastered bled motoring sing",
+		"conflated troubled sized hopping tanned falling hissing fizzed failing filing",
+		"happy sky", "relational conditional rational valenci hesitanci digitizer conformabli radicalli differentli vileli analogousli",
+		"vietnamization predication operator feudalism decisiveness hopefulness callousness formaliti sensitiviti sensibiliti",
+		"triplicate formative formalize electriciti electrical hopeful goodness",
+		"revival allowance inference airliner gyroscopic adjustable defensible irritant replacement adjustment dependent adoption homologou communism activate angulariti homologous effective bowdlerize",
+		"probate rate cease controll roll", "Über Straße naïve 日本語 x y z", "The addon for you and your friends",
+		"generalizations oscillators sensational", "KillAura, BedAura and AnchorAura", "NoFall no-slow FastUse",
+	}
+
+	dir := t.TempDir()
+	script, err := siteTemplates.ReadFile("templates/search.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scriptPath := filepath.Join(dir, "search.js")
+	if err := os.WriteFile(scriptPath, script, 0644); err != nil {
+		t.Fatal(err)
+	}
+	input, err := json.Marshal(texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(node, "-e", `const search = require(process.argv[1]);
+let input = "";
+process.stdin.on("data", (chunk) => input += chunk);
+process.stdin.on("end", () => console.log(JSON.stringify(JSON.parse(input).map(search.terms))));`, scriptPath)
+	cmd.Stdin = strings.NewReader(string(input))
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("failed to run search.js: %v", err)
+	}
+
+	var got [][]string
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, text := range texts {
+		if expected := searchTerms(text); !slices.Equal(got[i], expected) && len(got[i])+len(expected) != 0 {
+			t.Errorf("search.js terms of %q are %v, expected %v", text, got[i], expected)
+		}
+	}
+}
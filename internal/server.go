@@ -0,0 +1,354 @@
+package internal
+
+import (
+	"cmp"
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// ServeSorts are the orders the addons listed by the api can be sorted in, newest or highest first
+var ServeSorts = []string{"stars", "downloads", "updated"}
+
+// Server is a read-only JSON api over an addons file, which is reloaded whenever the file changes
+type Server struct {
+	path     string
+	addons   atomic.Pointer[servedAddons]
+	modified time.Time
+	size     int64
+}
+
+// the addons currently served, replaced as a whole on reload
+type servedAddons struct {
+	addons []*scanner.Addon
+	byId   map[string]*scanner.Addon
+}
+
+type AddonPage struct {
+	Total   int              `json:"total"`
+	Page    int              `json:"page"`
+	PerPage int              `json:"per_page"`
+	Addons  []*scanner.Addon `json:"addons"`
+}
+
+type SearchResult struct {
+	Addon *scanner.Addon `json:"addon"`
+	Score int            `json:"score"`
+	// modules, commands and HUD elements whose name or description matched
+	Matches []string `json:"matches"`
+}
+
+type SearchPage struct {
+	Total   int            `json:"total"`
+	Page    int            `json:"page"`
+	PerPage int            `json:"per_page"`
+	Results []SearchResult `json:"results"`
+}
+
+// NewServer loads the addons at path, failing if they cannot be read
+func NewServer(path string) (*Server, error) {
+	server := &Server{path: path}
+	if _, err := server.reload(); err != nil {
+		return nil, err
+	}
+
+	return server, nil
+}
+
+// reload reads the addons file again if its modification time or size changed, reporting whether it did
+func (s *Server) reload() (bool, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false, fmt.Errorf("Failed to read addons file: %v", err)
+	}
+
+	if s.addons.Load() != nil && info.ModTime().Equal(s.modified) && info.Size() == s.size {
+		return false, nil
+	}
+
+	addons, err := LoadAddons(s.path)
+	if err != nil {
+		return false, err
+	}
+
+	byId := make(map[string]*scanner.Addon, len(addons))
+	for _, addon := range addons {
+		byId[strings.ToLower(addon.Repo.Id)] = addon
+	}
+
+	s.addons.Store(&servedAddons{addons, byId})
+	s.modified = info.ModTime()
+	s.size = info.Size()
+
+	return true, nil
+}
+
+// Watch checks the addons file for changes every interval until stop is closed.
+// A file that fails to load keeps the previous addons served
+func (s *Server) Watch(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			reloaded, err := s.reload()
+			if err != nil {
+				slog.Warn("Failed to reload addons, still serving the previous ones", "stage", "serve", "error", err)
+			} else if reloaded {
+				slog.Info("Reloaded addons", "stage", "serve", "addons", len(s.addons.Load().addons))
+			}
+		}
+	}
+}
+
+// Handler returns the routes of the api:
+// GET /addons lists addons, GET /addons/{owner}/{repo} returns a single one and GET /search searches their features
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /addons", s.listAddons)
+	mux.HandleFunc("GET /addons/{owner}/{repo}", s.getAddon)
+	mux.HandleFunc("GET /search", s.search)
+
+	return mux
+}
+
+func (s *Server) listAddons(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	addons, err := filterServed(s.addons.Load().addons, query.Get("tag"), query.Get("version"), query.Get("verified"), query.Get("author"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := sortServed(addons, query.Get("sort")); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	page, perPage, err := pagination(query.Get("page"), query.Get("per_page"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, AddonPage{
+		Total:   len(addons),
+		Page:    page,
+		PerPage: perPage,
+		Addons:  paginate(addons, page, perPage),
+	})
+}
+
+func (s *Server) getAddon(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("owner") + "/" + r.PathValue("repo")
+
+	addon, ok := s.addons.Load().byId[strings.ToLower(id)]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("No addon %s", id))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, addon)
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	terms := strings.Fields(strings.ToLower(query.Get("q")))
+	if len(terms) == 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("No search query provided: /search?q=crystal"))
+		return
+	}
+	slices.Sort(terms)
+	terms = slices.Compact(terms)
+
+	addons, err := filterServed(s.addons.Load().addons, query.Get("tag"), query.Get("version"), query.Get("verified"), query.Get("author"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	page, perPage, err := pagination(query.Get("page"), query.Get("per_page"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var results []SearchResult
+	for _, addon := range addons {
+		if result, ok := searchAddon(addon, terms); ok {
+			results = append(results, result)
+		}
+	}
+
+	slices.SortStableFunc(results, func(a SearchResult, b SearchResult) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(b.Addon.Repo.Stars, a.Addon.Repo.Stars))
+	})
+
+	writeJSON(w, http.StatusOK, SearchPage{
+		Total:   len(results),
+		Page:    page,
+		PerPage: perPage,
+		Results: paginate(results, page, perPage),
+	})
+}
+
+// an addon matches when every term is found in the name or description of one of its modules, commands or HUD elements.
+// Names count twice as much as descriptions
+func searchAddon(addon *scanner.Addon, terms []string) (SearchResult, bool) {
+	result := SearchResult{Addon: addon, Matches: []string{}}
+	found := make(map[string]bool, len(terms))
+
+	for _, features := range [][]scanner.Feature{addon.Features.Modules, addon.Features.Commands, addon.Features.HudElements} {
+		for _, feature := range features {
+			name := strings.ToLower(feature.Name)
+			description := strings.ToLower(feature.Description)
+
+			matched := false
+			for _, term := range terms {
+				if strings.Contains(name, term) {
+					result.Score += 2
+					found[term] = true
+					matched = true
+				} else if strings.Contains(description, term) {
+					result.Score++
+					found[term] = true
+					matched = true
+				}
+			}
+
+			if matched {
+				result.Matches = append(result.Matches, feature.Name)
+			}
+		}
+	}
+
+	return result, len(found) == len(terms)
+}
+
+// returns the addons matching every filter that is set, in their original order
+func filterServed(addons []*scanner.Addon, tag string, version string, verified string, author string) ([]*scanner.Addon, error) {
+	var wantVerified *bool
+	if verified != "" {
+		value, err := strconv.ParseBool(verified)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid verified '%s', expected true or false", verified)
+		}
+		wantVerified = &value
+	}
+
+	filtered := make([]*scanner.Addon, 0, len(addons))
+	for _, addon := range addons {
+		if tag != "" && !hasTag(addonTags(addon), tag) {
+			continue
+		}
+
+		if version != "" && !slices.Contains(addonVersions(addon), version) {
+			continue
+		}
+
+		if wantVerified != nil && addon.Verified != *wantVerified {
+			continue
+		}
+
+		if author != "" && !strings.EqualFold(addon.Repo.Owner, author) &&
+			!slices.ContainsFunc(addon.Authors, func(a string) bool { return strings.EqualFold(strings.TrimSpace(a), author) }) {
+			continue
+		}
+
+		filtered = append(filtered, addon)
+	}
+
+	return filtered, nil
+}
+
+func sortServed(addons []*scanner.Addon, by string) error {
+	var compare func(a *scanner.Addon, b *scanner.Addon) int
+	switch by {
+	case "", "stars":
+		compare = func(a *scanner.Addon, b *scanner.Addon) int { return cmp.Compare(b.Repo.Stars, a.Repo.Stars) }
+	case "downloads":
+		compare = func(a *scanner.Addon, b *scanner.Addon) int { return cmp.Compare(b.Repo.Downloads, a.Repo.Downloads) }
+	case "updated":
+		// last update is RFC3339, which sorts the same as the time it holds
+		compare = func(a *scanner.Addon, b *scanner.Addon) int {
+			return strings.Compare(b.Repo.LastUpdate, a.Repo.LastUpdate)
+		}
+	default:
+		return fmt.Errorf("Unknown sort '%s', expected one of %s", by, strings.Join(ServeSorts, ", "))
+	}
+
+	slices.SortStableFunc(addons, func(a *scanner.Addon, b *scanner.Addon) int {
+		return cmp.Or(compare(a, b), strings.Compare(strings.ToLower(a.Repo.Id), strings.ToLower(b.Repo.Id)))
+	})
+
+	return nil
+}
+
+func pagination(pageValue string, perPageValue string) (int, int, error) {
+	page, perPage := 1, defaultPageSize
+
+	if pageValue != "" {
+		value, err := strconv.Atoi(pageValue)
+		if err != nil || value < 1 {
+			return 0, 0, fmt.Errorf("Invalid page '%s', expected a number from 1", pageValue)
+		}
+		page = value
+	}
+
+	if perPageValue != "" {
+		value, err := strconv.Atoi(perPageValue)
+		if err != nil || value < 1 || value > maxPageSize {
+			return 0, 0, fmt.Errorf("Invalid per_page '%s', expected a number from 1 to %d", perPageValue, maxPageSize)
+		}
+		perPage = value
+	}
+
+	return page, perPage, nil
+}
+
+// returns the items on the page, an empty slice past the last page
+func paginate[T any](items []T, page int, perPage int) []T {
+	start := min((page-1)*perPage, len(items))
+	end := min(start+perPage, len(items))
+
+	result := make([]T, 0, end-start)
+	return append(result, items[start:end]...)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("Failed to convert response to JSON: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
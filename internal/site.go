@@ -0,0 +1,314 @@
+package internal
+
+import (
+	"bytes"
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"embed"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"maps"
+	"net/url"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+//go:embed templates
+var siteTemplates embed.FS
+
+// fields shared by every page of the site
+type sitePage struct {
+	Title       string
+	GeneratedAt string
+	// relative path from the page to the root of the site, ends with a slash unless empty
+	Root string
+}
+
+type siteAddon struct {
+	Addon       *scanner.Addon
+	Path        string
+	Description string
+	Icon        string
+	Discord     string
+	Homepage    string
+	VersionList string
+	TagList     string
+	// inferred tags that are not in TagList, with their confidence
+	InferredTagList string
+	// custom and inferred tags, for filtering
+	FilterTagList string
+	// lowercase text the index filters on
+	Search  string
+	Authors []siteAuthorLink
+}
+
+type siteAuthorLink struct {
+	Name string
+	Path string
+}
+
+type siteFeatures struct {
+	Title    string
+	Features []scanner.Feature
+}
+
+type siteAuthor struct {
+	Name   string
+	Path   string
+	Addons []*siteAddon
+}
+
+type sitemap struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// RenderSite writes a static site of the addons to dir: an index.html that can be filtered in the browser,
+// one page per addon in addons/, one page per author in authors/, the search index with search.js to query it
+// and a sitemap.xml if baseURL is set.
+// Pages of addons and authors that are gone are removed
+func RenderSite(dir string, output *Output, baseURL string) error {
+	funcs := template.FuncMap{
+		"features": func(title string, features []scanner.Feature) siteFeatures {
+			return siteFeatures{title, features}
+		},
+	}
+
+	parse := func(name string) (*template.Template, error) {
+		tmpl, err := template.New(name).Funcs(funcs).ParseFS(siteTemplates, "templates/layout.html", "templates/"+name)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse template %s: %v", name, err)
+		}
+
+		return tmpl, nil
+	}
+
+	indexTemplate, err := parse("index.html")
+	if err != nil {
+		return err
+	}
+	addonTemplate, err := parse("addon.html")
+	if err != nil {
+		return err
+	}
+	authorTemplate, err := parse("author.html")
+	if err != nil {
+		return err
+	}
+
+	addons := make([]*siteAddon, 0, len(output.Addons))
+	authors := make(map[string]*siteAuthor)
+	var versions, tags []string
+	for _, addon := range output.Addons {
+		site := newSiteAddon(addon)
+		addons = append(addons, site)
+
+		for _, link := range site.Authors {
+			author, ok := authors[link.Path]
+			if !ok {
+				author = &siteAuthor{Name: link.Name, Path: link.Path}
+				authors[link.Path] = author
+			}
+			author.Addons = append(author.Addons, site)
+		}
+
+		for _, version := range addonVersions(addon) {
+			if !slices.Contains(versions, version) {
+				versions = append(versions, version)
+			}
+		}
+		for _, tag := range addonTags(addon) {
+			if !hasTag(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	slices.SortFunc(versions, func(a string, b string) int { return scanner.CompareMinecraftVersions(b, a) })
+	slices.SortFunc(tags, func(a string, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) })
+
+	written := make(map[string]struct{})
+	render := func(tmpl *template.Template, relativePath string, data any) error {
+		var buffer bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buffer, "layout", data); err != nil {
+			return fmt.Errorf("Failed to render %s: %v", relativePath, err)
+		}
+
+		fullPath := filepath.Join(dir, filepath.FromSlash(relativePath))
+		if err := writeIfChanged(fullPath, buffer.Bytes()); err != nil {
+			return fmt.Errorf("Failed to write %s: %v", relativePath, err)
+		}
+		written[fullPath] = struct{}{}
+
+		return nil
+	}
+
+	err = render(indexTemplate, "index.html", struct {
+		sitePage
+		Addons   []*siteAddon
+		Versions []string
+		Tags     []string
+	}{sitePage{"Meteor Addons", output.GeneratedAt, ""}, addons, versions, tags})
+	if err != nil {
+		return err
+	}
+
+	for _, addon := range addons {
+		err := render(addonTemplate, addon.Path, struct {
+			sitePage
+			*siteAddon
+		}{sitePage{addon.Addon.Name, output.GeneratedAt, siteRoot(addon.Path)}, addon})
+		if err != nil {
+			return err
+		}
+	}
+
+	authorPaths := slices.Sorted(maps.Keys(authors))
+	for _, authorPath := range authorPaths {
+		author := authors[authorPath]
+		err := render(authorTemplate, author.Path, struct {
+			sitePage
+			*siteAuthor
+		}{sitePage{author.Name, output.GeneratedAt, siteRoot(author.Path)}, author})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, subdir := range []string{"addons", "authors"} {
+		if err := removeStaleFiles(filepath.Join(dir, subdir), ".html", written); err != nil {
+			return fmt.Errorf("Failed to remove pages that are gone: %v", err)
+		}
+	}
+
+	style, err := siteTemplates.ReadFile("templates/style.css")
+	if err != nil {
+		return fmt.Errorf("Failed to read stylesheet: %v", err)
+	}
+	if err := writeIfChanged(filepath.Join(dir, "style.css"), style); err != nil {
+		return fmt.Errorf("Failed to write stylesheet: %v", err)
+	}
+
+	// the index search queries the stemmed search index with the same tokenizer and stemmer as the scanner
+	script, err := siteTemplates.ReadFile("templates/search.js")
+	if err != nil {
+		return fmt.Errorf("Failed to read search script: %v", err)
+	}
+	if err := writeIfChanged(filepath.Join(dir, "search.js"), script); err != nil {
+		return fmt.Errorf("Failed to write search script: %v", err)
+	}
+
+	searchIndex, err := json.Marshal(BuildSearchIndex(output.Addons))
+	if err != nil {
+		return fmt.Errorf("Failed to convert search index to JSON: %v", err)
+	}
+	if err := writeIfChanged(filepath.Join(dir, "search-index.json"), searchIndex); err != nil {
+		return fmt.Errorf("Failed to write search index: %v", err)
+	}
+
+	if baseURL == "" {
+		return nil
+	}
+
+	baseURL = strings.TrimSuffix(baseURL, "/") + "/"
+	pages := sitemap{URLs: []sitemapURL{{Loc: baseURL + "index.html", LastMod: output.GeneratedAt}}}
+	for _, addon := range addons {
+		pages.URLs = append(pages.URLs, sitemapURL{Loc: baseURL + addon.Path, LastMod: addon.Addon.Repo.LastUpdate})
+	}
+	for _, authorPath := range authorPaths {
+		pages.URLs = append(pages.URLs, sitemapURL{Loc: baseURL + authorPath})
+	}
+
+	data, err := xml.MarshalIndent(pages, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to convert sitemap to XML: %v", err)
+	}
+
+	if err := writeIfChanged(filepath.Join(dir, "sitemap.xml"), append([]byte(xml.Header), data...)); err != nil {
+		return fmt.Errorf("Failed to write sitemap: %v", err)
+	}
+
+	return nil
+}
+
+func newSiteAddon(addon *scanner.Addon) *siteAddon {
+	site := &siteAddon{
+		Addon:       addon,
+		Path:        strings.TrimSuffix(addonPath(addon.Repo.Id), ".json") + ".html",
+		Description: addonSummary(addon),
+		Icon:        webURL(preferCustom(addon.Custom.Icon, addon.Links.Icon)),
+		Discord:     webURL(preferCustom(addon.Custom.Discord, addon.Links.Discord)),
+		Homepage:    webURL(preferCustom(addon.Custom.Homepage, addon.Links.Homepage)),
+		VersionList: strings.Join(addonVersions(addon), ", "),
+		TagList:     strings.Join(addon.Custom.Tags, ", "),
+		// lowercase so the filter matches however the custom tags are spelled
+		FilterTagList: strings.ToLower(strings.Join(addonTags(addon), ", ")),
+	}
+
+	var inferred []string
+	for _, tag := range addon.InferredTags {
+		if !hasTag(addon.Custom.Tags, tag.Tag) {
+			inferred = append(inferred, fmt.Sprintf("%s (%.0f%%)", tag.Tag, tag.Confidence*100))
+		}
+	}
+	site.InferredTagList = strings.Join(inferred, ", ")
+
+	search := []string{addon.Name, addon.Repo.Id, site.Description}
+	search = append(search, addon.Authors...)
+	for _, features := range [][]scanner.Feature{addon.Features.Modules, addon.Features.Commands, addon.Features.HudElements} {
+		for _, feature := range features {
+			search = append(search, feature.Name)
+		}
+	}
+	site.Search = strings.ToLower(strings.Join(search, " "))
+
+	for _, author := range addon.Authors {
+		author = strings.TrimSpace(author)
+		if author == "" {
+			continue
+		}
+
+		// names that have no usable characters, like ones in other scripts, still get a stable file name
+		name := feedName(author)
+		if name == "" {
+			name = contentHash([]byte(author))
+		}
+
+		link := siteAuthorLink{author, path.Join("authors", name+".html")}
+		if !slices.ContainsFunc(site.Authors, func(other siteAuthorLink) bool { return other.Path == link.Path }) {
+			site.Authors = append(site.Authors, link)
+		}
+	}
+
+	return site
+}
+
+func preferCustom(custom string, scanned string) string {
+	if custom != "" {
+		return custom
+	}
+
+	return scanned
+}
+
+// drops urls that do not point to a web page, html/template would otherwise replace them with a placeholder
+func webURL(link string) string {
+	parsed, err := url.Parse(link)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return ""
+	}
+
+	return link
+}
+
+// returns the relative path from a page back to the root of the site
+func siteRoot(page string) string {
+	return strings.Repeat("../", strings.Count(page, "/"))
+}
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"cmp"
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SortOrders are the orders addons can be sorted by, the first one is the default
+var SortOrders = []string{"id", "stars", "name"}
+
+// SortAddons sorts addons by the given order and sorts the features of every addon,
+// so the output does not change between runs unless the addons did.
+// Downloads keep the stable releases first order they were found in
+func SortAddons(addons []*scanner.Addon, by string) error {
+	var compare func(a, b *scanner.Addon) int
+	switch by {
+	case "", "id":
+		compare = func(a, b *scanner.Addon) int { return 0 }
+	case "stars":
+		compare = func(a, b *scanner.Addon) int { return cmp.Compare(b.Repo.Stars, a.Repo.Stars) }
+	case "name":
+		compare = func(a, b *scanner.Addon) int {
+			return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		}
+	default:
+		return fmt.Errorf("Unknown sort order '%s', expected one of %s", by, strings.Join(SortOrders, ", "))
+	}
+
+	for _, addon := range addons {
+		sortFeatures(addon.Features.Modules)
+		sortFeatures(addon.Features.Commands)
+		sortFeatures(addon.Features.HudElements)
+		slices.Sort(addon.Features.Tabs)
+		slices.Sort(addon.Features.Themes)
+	}
+
+	// ties are broken by repo id, which is unique
+	slices.SortStableFunc(addons, func(a, b *scanner.Addon) int {
+		if order := compare(a, b); order != 0 {
+			return order
+		}
+
+		return cmp.Compare(strings.ToLower(a.Repo.Id), strings.ToLower(b.Repo.Id))
+	})
+
+	return nil
+}
+
+func sortFeatures(features []scanner.Feature) {
+	slices.SortFunc(features, func(a, b scanner.Feature) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Description, b.Description))
+	})
+}
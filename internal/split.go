@@ -0,0 +1,159 @@
+package internal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Index is the slim list of addons written next to the per-addon files
+type Index struct {
+	SchemaVersion  int           `json:"schema_version"`
+	GeneratedAt    string        `json:"generated_at"`
+	ScannerVersion string        `json:"scanner_version"`
+	ConfigHash     string        `json:"config_hash"`
+	Summary        OutputSummary `json:"summary"`
+	Addons         []IndexEntry  `json:"addons"`
+}
+
+type IndexEntry struct {
+	Id    string   `json:"id"`
+	Name  string   `json:"name"`
+	Icon  string   `json:"icon"`
+	Stars int      `json:"stars"`
+	Tags  []string `json:"tags"`
+	// tags guessed by the scanner that are not in tags
+	InferredTags []string `json:"inferred_tags"`
+	McVersion    string   `json:"mc_version"`
+	Verified     bool     `json:"verified"`
+	// path of the full addon, relative to the index
+	Path string `json:"path"`
+	// hash of the content of the full addon, changes whenever the file does
+	Hash string `json:"hash"`
+}
+
+// WriteSplitOutput writes an index.json and one addons/<owner>/<repo>.json per addon to dir.
+// Files whose content did not change are left alone and files of addons that are gone are removed
+func WriteSplitOutput(dir string, output *Output, pretty bool) error {
+	index := Index{
+		SchemaVersion:  output.SchemaVersion,
+		GeneratedAt:    output.GeneratedAt,
+		ScannerVersion: output.ScannerVersion,
+		ConfigHash:     output.ConfigHash,
+		Summary:        output.Summary,
+		Addons:         make([]IndexEntry, 0, len(output.Addons)),
+	}
+
+	written := make(map[string]struct{})
+	for _, addon := range output.Addons {
+		data, err := marshal(addon, pretty)
+		if err != nil {
+			return fmt.Errorf("Failed to convert %s to JSON: %v", addon.Repo.Id, err)
+		}
+
+		relativePath := addonPath(addon.Repo.Id)
+		fullPath := filepath.Join(dir, filepath.FromSlash(relativePath))
+		if err := writeIfChanged(fullPath, data); err != nil {
+			return fmt.Errorf("Failed to write %s: %v", relativePath, err)
+		}
+		written[fullPath] = struct{}{}
+
+		tags := addon.Custom.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+
+		index.Addons = append(index.Addons, IndexEntry{
+			Id:           addon.Repo.Id,
+			Name:         addon.Name,
+			Icon:         addon.Links.Icon,
+			Stars:        addon.Repo.Stars,
+			Tags:         tags,
+			InferredTags: inferredTagNames(addon),
+			McVersion:    addon.McVersion,
+			Verified:     addon.Verified,
+			Path:         relativePath,
+			Hash:         contentHash(data),
+		})
+	}
+
+	if err := removeStaleFiles(filepath.Join(dir, "addons"), ".json", written); err != nil {
+		return fmt.Errorf("Failed to remove files of removed addons: %v", err)
+	}
+
+	data, err := marshal(index, pretty)
+	if err != nil {
+		return fmt.Errorf("Failed to convert index to JSON: %v", err)
+	}
+
+	if err := writeIfChanged(filepath.Join(dir, "index.json"), data); err != nil {
+		return fmt.Errorf("Failed to write index: %v", err)
+	}
+
+	return nil
+}
+
+// returns the path of the file of an addon, lowercase so urls do not depend on the casing of the repo
+func addonPath(id string) string {
+	owner, repo, _ := strings.Cut(strings.ToLower(id), "/")
+	return path.Join("addons", owner, repo+".json")
+}
+
+// returns the first 16 hex characters of the sha256 of data
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+func marshal(v any, pretty bool) ([]byte, error) {
+	if pretty {
+		return json.MarshalIndent(v, "", "  ")
+	}
+
+	return json.Marshal(v)
+}
+
+// keeps the modification time of unchanged files, so syncing to a CDN only uploads what changed
+func writeIfChanged(file string, data []byte) error {
+	if existing, err := os.ReadFile(file); err == nil && bytes.Equal(existing, data) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+
+	return WriteFile(file, data)
+}
+
+// removes the files ending in suffix under dir that are not in keep
+func removeStaleFiles(dir string, suffix string, keep map[string]struct{}) error {
+	err := filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || !strings.HasSuffix(file, suffix) {
+			return nil
+		}
+
+		if _, ok := keep[file]; ok {
+			return nil
+		}
+
+		return os.Remove(file)
+	})
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
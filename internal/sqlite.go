@@ -0,0 +1,222 @@
+package internal
+
+import (
+	"database/sql"
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SQLiteSchemaVersion is stored in user_version and bumped whenever a table or column changes
+// 2 added the source and confidence of tags
+const SQLiteSchemaVersion = 2
+
+var sqliteSchema = []string{
+	`CREATE TABLE meta (
+		schema_version INTEGER NOT NULL,
+		output_schema_version INTEGER NOT NULL,
+		generated_at TEXT NOT NULL,
+		scanner_version TEXT NOT NULL,
+		config_hash TEXT NOT NULL
+	)`,
+	`CREATE TABLE addons (
+		id TEXT PRIMARY KEY COLLATE NOCASE,
+		name TEXT NOT NULL,
+		description TEXT NOT NULL,
+		mc_version TEXT NOT NULL,
+		verified INTEGER NOT NULL,
+		owner TEXT NOT NULL,
+		repo_name TEXT NOT NULL,
+		archived INTEGER NOT NULL,
+		fork INTEGER NOT NULL,
+		forks INTEGER NOT NULL,
+		stars INTEGER NOT NULL,
+		downloads INTEGER NOT NULL,
+		last_update TEXT NOT NULL,
+		creation_date TEXT NOT NULL,
+		feature_count INTEGER NOT NULL,
+		github TEXT NOT NULL,
+		latest_release TEXT NOT NULL,
+		discord TEXT NOT NULL,
+		homepage TEXT NOT NULL,
+		icon TEXT NOT NULL,
+		custom_description TEXT NOT NULL,
+		custom_icon TEXT NOT NULL,
+		custom_discord TEXT NOT NULL,
+		custom_homepage TEXT NOT NULL,
+		last_scanned TEXT NOT NULL,
+		stale INTEGER NOT NULL,
+		trending REAL,
+		quality REAL
+	)`,
+	`CREATE TABLE authors (addon_id TEXT NOT NULL REFERENCES addons(id), name TEXT NOT NULL)`,
+	`CREATE TABLE modules (addon_id TEXT NOT NULL REFERENCES addons(id), name TEXT NOT NULL, description TEXT NOT NULL)`,
+	`CREATE TABLE commands (addon_id TEXT NOT NULL REFERENCES addons(id), name TEXT NOT NULL, description TEXT NOT NULL)`,
+	`CREATE TABLE hud_elements (addon_id TEXT NOT NULL REFERENCES addons(id), name TEXT NOT NULL, description TEXT NOT NULL)`,
+	`CREATE TABLE tabs (addon_id TEXT NOT NULL REFERENCES addons(id), name TEXT NOT NULL)`,
+	`CREATE TABLE themes (addon_id TEXT NOT NULL REFERENCES addons(id), name TEXT NOT NULL)`,
+	// source is "custom" for meteor-addon-list.json and "inferred" for tags guessed by the scanner, which have a confidence
+	`CREATE TABLE tags (addon_id TEXT NOT NULL REFERENCES addons(id), tag TEXT NOT NULL, source TEXT NOT NULL, confidence REAL)`,
+	// source is "scanned" for the version read from gradle and "custom" for meteor-addon-list.json
+	`CREATE TABLE supported_versions (addon_id TEXT NOT NULL REFERENCES addons(id), version TEXT NOT NULL, source TEXT NOT NULL)`,
+	`CREATE TABLE downloads (addon_id TEXT NOT NULL REFERENCES addons(id), url TEXT NOT NULL)`,
+	`CREATE INDEX addons_name ON addons(name COLLATE NOCASE)`,
+	`CREATE INDEX addons_mc_version ON addons(mc_version)`,
+	`CREATE INDEX authors_addon ON authors(addon_id)`,
+	`CREATE INDEX authors_name ON authors(name COLLATE NOCASE)`,
+	`CREATE INDEX modules_addon ON modules(addon_id)`,
+	`CREATE INDEX modules_name ON modules(name COLLATE NOCASE)`,
+	`CREATE INDEX commands_addon ON commands(addon_id)`,
+	`CREATE INDEX commands_name ON commands(name COLLATE NOCASE)`,
+	`CREATE INDEX hud_elements_addon ON hud_elements(addon_id)`,
+	`CREATE INDEX hud_elements_name ON hud_elements(name COLLATE NOCASE)`,
+	`CREATE INDEX tabs_addon ON tabs(addon_id)`,
+	`CREATE INDEX themes_addon ON themes(addon_id)`,
+	`CREATE INDEX tags_addon ON tags(addon_id)`,
+	`CREATE INDEX tags_tag ON tags(tag COLLATE NOCASE)`,
+	`CREATE INDEX supported_versions_addon ON supported_versions(addon_id)`,
+	`CREATE INDEX supported_versions_version ON supported_versions(version)`,
+	`CREATE INDEX downloads_addon ON downloads(addon_id)`,
+}
+
+// ExportSQLite writes the output into a new SQLite database at path, replacing it once fully written
+func ExportSQLite(path string, output *Output) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("Failed to export to SQLite: %v", err)
+	}
+	tempPath := file.Name()
+	file.Close()
+
+	if err := writeSQLite(tempPath, output); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("Failed to export to SQLite: %v", err)
+	}
+
+	if err := os.Rename(tempPath, path); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("Failed to export to SQLite: %v", err)
+	}
+
+	return nil
+}
+
+func writeSQLite(path string, output *Output) error {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range sqliteSchema {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", SQLiteSchemaVersion)); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`INSERT INTO meta VALUES (?, ?, ?, ?, ?)`,
+		SQLiteSchemaVersion, output.SchemaVersion, output.GeneratedAt, output.ScannerVersion, output.ConfigHash); err != nil {
+		return err
+	}
+
+	for _, addon := range output.Addons {
+		if err := insertAddon(tx, addon); err != nil {
+			return fmt.Errorf("Failed to insert %s: %v", addon.Repo.Id, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func insertAddon(tx *sql.Tx, addon *scanner.Addon) error {
+	var trending, quality sql.NullFloat64
+	if addon.Score != nil {
+		trending = sql.NullFloat64{Float64: addon.Score.Trending, Valid: true}
+		quality = sql.NullFloat64{Float64: addon.Score.Quality, Valid: true}
+	}
+
+	_, err := tx.Exec(`INSERT INTO addons VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		addon.Repo.Id, addon.Name, addon.Description, addon.McVersion, addon.Verified,
+		addon.Repo.Owner, addon.Repo.Name, addon.Repo.Archived, addon.Repo.Fork, addon.Repo.Forks, addon.Repo.Stars, addon.Repo.Downloads,
+		addon.Repo.LastUpdate, addon.Repo.CreationDate, addon.Features.FeatureCount,
+		addon.Links.Github, addon.Links.LatestRelease, addon.Links.Discord, addon.Links.Homepage, addon.Links.Icon,
+		addon.Custom.Description, addon.Custom.Icon, addon.Custom.Discord, addon.Custom.Homepage,
+		addon.LastScanned, addon.Stale, trending, quality)
+	if err != nil {
+		return err
+	}
+
+	id := addon.Repo.Id
+
+	for _, author := range addon.Authors {
+		if _, err := tx.Exec(`INSERT INTO authors VALUES (?, ?)`, id, author); err != nil {
+			return err
+		}
+	}
+
+	features := map[string][]scanner.Feature{
+		"modules":      addon.Features.Modules,
+		"commands":     addon.Features.Commands,
+		"hud_elements": addon.Features.HudElements,
+	}
+	for table, list := range features {
+		for _, feature := range list {
+			if _, err := tx.Exec(`INSERT INTO `+table+` VALUES (?, ?, ?)`, id, feature.Name, feature.Description); err != nil {
+				return err
+			}
+		}
+	}
+
+	names := map[string][]string{
+		"tabs":      addon.Features.Tabs,
+		"themes":    addon.Features.Themes,
+		"downloads": addon.Links.Downloads,
+	}
+	for table, list := range names {
+		for _, name := range list {
+			if _, err := tx.Exec(`INSERT INTO `+table+` VALUES (?, ?)`, id, name); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, tag := range addon.Custom.Tags {
+		if _, err := tx.Exec(`INSERT INTO tags VALUES (?, ?, 'custom', NULL)`, id, tag); err != nil {
+			return err
+		}
+	}
+	for _, tag := range addon.InferredTags {
+		if hasTag(addon.Custom.Tags, tag.Tag) {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT INTO tags VALUES (?, ?, 'inferred', ?)`, id, tag.Tag, tag.Confidence); err != nil {
+			return err
+		}
+	}
+
+	if addon.McVersion != "" {
+		if _, err := tx.Exec(`INSERT INTO supported_versions VALUES (?, ?, 'scanned')`, id, addon.McVersion); err != nil {
+			return err
+		}
+	}
+	for _, version := range addon.Custom.SupportedVersions {
+		if _, err := tx.Exec(`INSERT INTO supported_versions VALUES (?, ?, 'custom')`, id, version); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// CarryForwardStaleAddons keeps addons from the previous scan whose repo failed transiently in this scan.
+// Carried forward addons are marked as stale and dropped once their last successful scan is older than retentionDays.
+// Returns the updated addons
+func CarryForwardStaleAddons(addons []*scanner.Addon, previous []*scanner.Addon, retryLog *scanner.RetryLog, retentionDays int, now time.Time) []*scanner.Addon {
+	scanned := make(map[string]struct{}, len(addons))
+	for _, addon := range addons {
+		scanned[strings.ToLower(addon.Repo.Id)] = struct{}{}
+	}
+
+	for _, addon := range previous {
+		if _, ok := scanned[strings.ToLower(addon.Repo.Id)]; ok {
+			continue
+		}
+
+		entry, ok := retryLog.Get(addon.Repo.Id)
+		if !ok || entry.Class != scanner.Transient {
+			continue
+		}
+
+		lastScanned, err := time.Parse(time.RFC3339, addon.LastScanned)
+		if err != nil {
+			slog.Info("Missing last successful scan time, dropped", "repo", addon.Repo.Id, "stage", "stale")
+			continue
+		}
+
+		if lastScanned.AddDate(0, 0, retentionDays).Before(now) {
+			slog.Info("Stale for too long, dropped", "repo", addon.Repo.Id, "stage", "stale", "retention_days", retentionDays)
+			continue
+		}
+
+		addon.Stale = true
+		addons = append(addons, addon)
+		scanned[strings.ToLower(addon.Repo.Id)] = struct{}{}
+		slog.Info("Failed transiently, kept previous entry", "repo", addon.Repo.Id, "stage", "stale", "reason", entry.Reason, "last_scanned", addon.LastScanned)
+	}
+
+	return addons
+}
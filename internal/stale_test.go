@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"testing"
+	"time"
+)
+
+func TestStaleAddonsAreDroppedAfterRetention(t *testing.T) {
+	now := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+
+	newAddon := func(id string, lastScanned time.Time) *scanner.Addon {
+		addon := &scanner.Addon{LastScanned: lastScanned.Format(time.RFC3339)}
+		addon.Repo.Id = id
+		return addon
+	}
+
+	// a fresh retry log, like a scan without -retry-log, where every failure is a first failure
+	retryLog := scanner.NewRetryLog()
+	for _, repo := range []string{"owner/recent", "owner/old"} {
+		retryLog.Entries[repo] = &scanner.RetryEntry{Class: scanner.Transient, Reason: "bad gateway", FirstFailure: now, LastFailure: now, Attempts: 1}
+	}
+
+	previous := []*scanner.Addon{newAddon("owner/recent", now.AddDate(0, 0, -1)), newAddon("owner/old", now.AddDate(0, 0, -5))}
+	addons := CarryForwardStaleAddons(nil, previous, retryLog, 3, now)
+
+	if len(addons) != 1 || addons[0].Repo.Id != "owner/recent" {
+		t.Fatalf("expected only owner/recent to be carried forward, got %d addons", len(addons))
+	}
+	if !addons[0].Stale || addons[0].LastScanned != previous[0].LastScanned {
+		t.Errorf("carried forward addon must be stale and keep its last successful scan time, got stale %v and %s", addons[0].Stale, addons[0].LastScanned)
+	}
+}
@@ -0,0 +1,183 @@
+package internal
+
+import "strings"
+
+// suffix rules of a step of the Porter stemmer, longest first since only the longest matching suffix is considered
+type stemRule struct {
+	suffix      string
+	replacement string
+}
+
+var stemStep2 = []stemRule{
+	{"ational", "ate"}, {"fulness", "ful"}, {"iveness", "ive"}, {"ization", "ize"}, {"ousness", "ous"},
+	{"biliti", "ble"}, {"tional", "tion"},
+	{"alism", "al"}, {"aliti", "al"}, {"ation", "ate"}, {"entli", "ent"}, {"iviti", "ive"}, {"ousli", "ous"},
+	{"abli", "able"}, {"alli", "al"}, {"anci", "ance"}, {"ator", "ate"}, {"enci", "ence"}, {"izer", "ize"},
+	{"eli", "e"},
+}
+
+var stemStep3 = []stemRule{
+	{"alize", "al"}, {"ative", ""}, {"icate", "ic"}, {"iciti", "ic"},
+	{"ical", "ic"}, {"ness", ""},
+	{"ful", ""},
+}
+
+var stemStep4 = []string{
+	"ement",
+	"ance", "ence", "able", "ible", "ment",
+	"ant", "ent", "ion", "ism", "ate", "iti", "ous", "ive", "ize",
+	"al", "er", "ic", "ou",
+}
+
+// Stem reduces an English word to its stem with the Porter stemmer, so "crystals" and "crystal" match.
+// Words that are not all lowercase ascii letters are returned unchanged
+func Stem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	// step 1a, plurals
+	switch {
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "ies"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(word, "ss"):
+	case strings.HasSuffix(word, "s"):
+		word = word[:len(word)-1]
+	}
+
+	// step 1b, past tenses and gerunds
+	if strings.HasSuffix(word, "eed") {
+		if stemMeasure(word[:len(word)-3]) > 0 {
+			word = word[:len(word)-1]
+		}
+	} else if stem, ok := cutVowelSuffix(word, "ed", "ing"); ok {
+		word = stem
+		switch {
+		case strings.HasSuffix(word, "at"), strings.HasSuffix(word, "bl"), strings.HasSuffix(word, "iz"):
+			word += "e"
+		case endsWithDoubleConsonant(word) && !strings.ContainsAny(word[len(word)-1:], "lsz"):
+			word = word[:len(word)-1]
+		case stemMeasure(word) == 1 && endsWithCVC(word):
+			word += "e"
+		}
+	}
+
+	// step 1c
+	if strings.HasSuffix(word, "y") && hasVowel(word[:len(word)-1]) {
+		word = word[:len(word)-1] + "i"
+	}
+
+	word = applyStemRules(word, stemStep2)
+	word = applyStemRules(word, stemStep3)
+
+	// step 4, removes suffixes of stems that are long enough
+	for _, suffix := range stemStep4 {
+		if !strings.HasSuffix(word, suffix) {
+			continue
+		}
+
+		stem := word[:len(word)-len(suffix)]
+		if stemMeasure(stem) > 1 && (suffix != "ion" || strings.HasSuffix(stem, "s") || strings.HasSuffix(stem, "t")) {
+			word = stem
+		}
+		break
+	}
+
+	// step 5, a final e and double l
+	if strings.HasSuffix(word, "e") {
+		stem := word[:len(word)-1]
+		if measure := stemMeasure(stem); measure > 1 || (measure == 1 && !endsWithCVC(stem)) {
+			word = stem
+		}
+	}
+	if strings.HasSuffix(word, "ll") && stemMeasure(word) > 1 {
+		word = word[:len(word)-1]
+	}
+
+	return word
+}
+
+// replaces the longest suffix in rules if what is left has a measure above 0
+func applyStemRules(word string, rules []stemRule) string {
+	for _, rule := range rules {
+		if !strings.HasSuffix(word, rule.suffix) {
+			continue
+		}
+
+		stem := word[:len(word)-len(rule.suffix)]
+		if stemMeasure(stem) > 0 {
+			return stem + rule.replacement
+		}
+		return word
+	}
+
+	return word
+}
+
+// removes the first of suffixes the word ends with, if what is left contains a vowel
+func cutVowelSuffix(word string, suffixes ...string) (string, bool) {
+	for _, suffix := range suffixes {
+		if stem, ok := strings.CutSuffix(word, suffix); ok && hasVowel(stem) {
+			return stem, true
+		}
+	}
+
+	return word, false
+}
+
+// y is a consonant at the start of a word or after a vowel
+func isConsonant(word string, i int) bool {
+	switch word[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !isConsonant(word, i-1)
+	default:
+		return true
+	}
+}
+
+// returns m in the form [C](VC){m}[V] of the word
+func stemMeasure(word string) int {
+	measure := 0
+	previousVowel := false
+	for i := range len(word) {
+		vowel := !isConsonant(word, i)
+		if previousVowel && !vowel {
+			measure++
+		}
+		previousVowel = vowel
+	}
+
+	return measure
+}
+
+func hasVowel(word string) bool {
+	for i := range len(word) {
+		if !isConsonant(word, i) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func endsWithDoubleConsonant(word string) bool {
+	n := len(word)
+	return n >= 2 && word[n-1] == word[n-2] && isConsonant(word, n-1)
+}
+
+// reports whether the word ends with consonant, vowel, consonant where the last one is not w, x or y, like "hop"
+func endsWithCVC(word string) bool {
+	n := len(word)
+	if n < 3 || !isConsonant(word, n-1) || isConsonant(word, n-2) || !isConsonant(word, n-3) {
+		return false
+	}
+
+	return !strings.ContainsAny(word[n-1:], "wxy")
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// StreamRecord is one line of an NDJSON stream, either an addon or the summary that closes the stream
+type StreamRecord struct {
+	Type    string         `json:"type"`
+	Addon   *scanner.Addon `json:"addon,omitempty"`
+	Summary *StreamSummary `json:"summary,omitempty"`
+}
+
+type StreamSummary struct {
+	FinishedAt string `json:"finished_at"`
+	Repos      int    `json:"repos"`
+	Addons     int    `json:"addons"`
+}
+
+// Stream writes addons as NDJSON while a scan runs, every line is written as soon as it is
+// produced so a crash still leaves the addons parsed until then. It is safe for concurrent use
+type Stream struct {
+	writer io.Writer
+	closer io.Closer
+	count  int
+	mu     sync.Mutex
+}
+
+// CreateStream creates or truncates the file at path, "-" streams to stdout
+func CreateStream(path string) (*Stream, error) {
+	if path == "-" {
+		return &Stream{writer: os.Stdout}, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create stream: %v", err)
+	}
+
+	return &Stream{writer: file, closer: file}, nil
+}
+
+func (s *Stream) write(record StreamRecord) error {
+	bytes, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.writer.Write(append(bytes, '\n'))
+	return err
+}
+
+// WriteAddon adds an addon to the stream
+func (s *Stream) WriteAddon(addon *scanner.Addon) error {
+	if err := s.write(StreamRecord{Type: "addon", Addon: addon}); err != nil {
+		return fmt.Errorf("Failed to stream %s: %v", addon.Repo.Id, err)
+	}
+
+	s.mu.Lock()
+	s.count++
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Close writes the summary record and closes the stream, a stream without a summary was cut short
+func (s *Stream) Close(repos int, now time.Time) error {
+	s.mu.Lock()
+	count := s.count
+	s.mu.Unlock()
+
+	summary := StreamSummary{
+		FinishedAt: now.UTC().Format(time.RFC3339),
+		Repos:      repos,
+		Addons:     count,
+	}
+
+	err := s.write(StreamRecord{Type: "summary", Summary: &summary})
+	if s.closer != nil {
+		if closeErr := s.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to close stream: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package internal
+
+import (
+	"cmp"
+	"dev/cqb13/meteor-addon-scanner/scanner"
+	"math"
+	"slices"
+	"strings"
+)
+
+// tags with a lower confidence are not inferred when the config does not set a minimum
+const defaultMinConfidence = 0.3
+
+// how much a keyword match in each source counts towards a tag
+const (
+	categoryEvidence           = 3
+	topicEvidence              = 3
+	featureNameEvidence        = 2
+	featureDescriptionEvidence = 1
+)
+
+// evidence at which the confidence reaches about 63%, it approaches 100% as evidence grows
+const evidenceScale = 6
+
+// DefaultTagKeywords are the keywords a tag is inferred from, keyed by the lowercase tag name.
+// Keywords are stemmed before matching and may span several words
+var DefaultTagKeywords = map[string][]string{
+	"pvp":        {"pvp", "combat", "crystal", "aura", "anchor", "surround", "criticals", "totem", "offhand", "burrow", "bed aura", "trap", "auto armor", "hole", "pearl", "velocity"},
+	"utility":    {"utility", "util", "inventory", "notifier", "reconnect", "auto log", "tool", "sorter", "replenish", "chest"},
+	"theme":      {"theme", "gui", "color scheme", "skin"},
+	"render":     {"render", "esp", "tracer", "xray", "chams", "nametag", "fullbright", "highlight", "overlay", "shader", "outline", "breadcrumb", "trail"},
+	"movement":   {"movement", "fly", "flight", "speed", "elytra", "step", "jump", "jesus", "spider", "sprint", "strafe", "phase", "no fall", "bhop", "boat"},
+	"building":   {"building", "builder", "scaffold", "schematic", "printer", "highway", "litematica", "place"},
+	"world":      {"world", "seed", "chunk", "biome", "structure", "waypoint", "stash", "base finder", "portal", "nuker"},
+	"misc":       {"misc", "miscellaneous"},
+	"qol":        {"qol", "quality of life", "convenience", "zoom", "tweak", "tooltip", "auto respawn"},
+	"exploit":    {"exploit", "dupe", "crash", "bypass", "packet", "anticheat", "anti cheat", "illegal", "disabler", "book ban"},
+	"fun":        {"fun", "spam", "troll", "meme", "dance", "emote", "music", "prank", "derp"},
+	"automation": {"automation", "bot", "baritone", "farm", "fish", "auto mine", "afk", "auto walk", "auto eat", "macro", "script"},
+}
+
+// InferTags guesses the tags of every addon from its categories, repo topics and the names and descriptions of its
+// features, using the keywords in the config or the default ones. Tags below the minimum confidence are left out
+func InferTags(addons []*scanner.Addon, config *scanner.Config) {
+	minConfidence := config.TagInference.MinConfidence
+	if minConfidence <= 0 {
+		minConfidence = defaultMinConfidence
+	}
+
+	keywords := tagKeywords(config)
+
+	for _, addon := range addons {
+		addon.InferredTags = inferAddonTags(addon, keywords, minConfidence)
+	}
+}
+
+// returns the inferred tags of an addon that are not among its custom tags, highest confidence first
+func inferredTagNames(addon *scanner.Addon) []string {
+	tags := []string{}
+	for _, inferred := range addon.InferredTags {
+		if !hasTag(addon.Custom.Tags, inferred.Tag) {
+			tags = append(tags, inferred.Tag)
+		}
+	}
+
+	return tags
+}
+
+// returns the custom tags of an addon followed by the inferred ones
+func addonTags(addon *scanner.Addon) []string {
+	return append(slices.Clone(addon.Custom.Tags), inferredTagNames(addon)...)
+}
+
+func hasTag(tags []string, tag string) bool {
+	return slices.ContainsFunc(tags, func(t string) bool { return strings.EqualFold(t, tag) })
+}
+
+// returns the stemmed keywords of every tag, keyed by the tag
+func tagKeywords(config *scanner.Config) map[scanner.Tag][]string {
+	configured := make(map[string][]string)
+	for tag, words := range config.TagInference.Keywords {
+		configured[strings.ToLower(tag)] = words
+	}
+
+	keywords := make(map[scanner.Tag][]string)
+	for _, tag := range scanner.Tags() {
+		words, ok := configured[strings.ToLower(tag.String())]
+		if !ok {
+			words = DefaultTagKeywords[strings.ToLower(tag.String())]
+		}
+
+		for _, word := range words {
+			if stemmed := stemmedText(word); strings.TrimSpace(stemmed) != "" {
+				keywords[tag] = append(keywords[tag], stemmed)
+			}
+		}
+	}
+
+	return keywords
+}
+
+func inferAddonTags(addon *scanner.Addon, keywords map[scanner.Tag][]string, minConfidence float64) []scanner.InferredTag {
+	evidence := make(map[scanner.Tag]int)
+
+	// every source counts once per tag, however many of its keywords it contains
+	add := func(text string, weight int) {
+		stemmed := stemmedText(text)
+		for tag, words := range keywords {
+			if slices.ContainsFunc(words, func(word string) bool { return strings.Contains(stemmed, word) }) {
+				evidence[tag] += weight
+			}
+		}
+	}
+
+	for _, category := range addon.Features.Categories {
+		add(category, categoryEvidence)
+	}
+	for _, topic := range addon.Repo.Topics {
+		add(topic, topicEvidence)
+	}
+	for _, features := range [][]scanner.Feature{addon.Features.Modules, addon.Features.Commands, addon.Features.HudElements} {
+		for _, feature := range features {
+			add(feature.Name, featureNameEvidence)
+			add(feature.Description, featureDescriptionEvidence)
+		}
+	}
+	// themes are registered explicitly, so each one is as strong as a category
+	evidence[scanner.Theme] += categoryEvidence * len(addon.Features.Themes)
+
+	var tags []scanner.InferredTag
+	for tag, amount := range evidence {
+		confidence := math.Round((1-math.Exp(-float64(amount)/evidenceScale))*100) / 100
+		if amount > 0 && confidence >= minConfidence {
+			tags = append(tags, scanner.InferredTag{Tag: tag.String(), Confidence: confidence})
+		}
+	}
+
+	slices.SortFunc(tags, func(a scanner.InferredTag, b scanner.InferredTag) int {
+		return cmp.Or(cmp.Compare(b.Confidence, a.Confidence), strings.Compare(a.Tag, b.Tag))
+	})
+
+	return tags
+}
+
+// tokenizes and stems text into words separated and surrounded by single spaces,
+// so a keyword only matches whole words, like "aura" in "kill aura" but not in "laurel"
+func stemmedText(text string) string {
+	var words []string
+	for _, token := range Tokenize(text) {
+		words = append(words, Stem(token))
+	}
+
+	return " " + strings.Join(words, " ") + " "
+}
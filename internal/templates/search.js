@@ -0,0 +1,242 @@
+// client-side version of the search index queries, tokenize, stem and terms have to match Tokenize, Stem and searchTerms in internal/search.go
+(function (exports) {
+  var stopWords = {
+    a: true, an: true, and: true, are: true, as: true, at: true, be: true, by: true, for: true,
+    from: true, in: true, is: true, it: true, of: true, on: true, or: true, that: true, the: true,
+    this: true, to: true, with: true, you: true, your: true
+  };
+
+  var step2 = [
+    ["ational", "ate"], ["fulness", "ful"], ["iveness", "ive"], ["ization", "ize"], ["ousness", "ous"],
+    ["biliti", "ble"], ["tional", "tion"],
+    ["alism", "al"], ["aliti", "al"], ["ation", "ate"], ["entli", "ent"], ["iviti", "ive"], ["ousli", "ous"],
+    ["abli", "able"], ["alli", "al"], ["anci", "ance"], ["ator", "ate"], ["enci", "ence"], ["izer", "ize"],
+    ["eli", "e"]
+  ];
+
+  var step3 = [
+    ["alize", "al"], ["ative", ""], ["icate", "ic"], ["iciti", "ic"],
+    ["ical", "ic"], ["ness", ""],
+    ["ful", ""]
+  ];
+
+  var step4 = [
+    "ement",
+    "ance", "ence", "able", "ible", "ment",
+    "ant", "ent", "ion", "ism", "ate", "iti", "ous", "ive", "ize",
+    "al", "er", "ic", "ou"
+  ];
+
+  var letterOrDigit = /[\p{L}\p{Nd}]/u;
+  var upper = /\p{Lu}/u;
+  var lower = /\p{Ll}/u;
+
+  function endsWith(word, suffix) {
+    return word.length >= suffix.length && word.slice(word.length - suffix.length) === suffix;
+  }
+
+  function isConsonant(word, i) {
+    switch (word[i]) {
+      case "a": case "e": case "i": case "o": case "u":
+        return false;
+      case "y":
+        return i === 0 || !isConsonant(word, i - 1);
+      default:
+        return true;
+    }
+  }
+
+  function measure(word) {
+    var m = 0;
+    var previousVowel = false;
+    for (var i = 0; i < word.length; i++) {
+      var vowel = !isConsonant(word, i);
+      if (previousVowel && !vowel) {
+        m++;
+      }
+      previousVowel = vowel;
+    }
+    return m;
+  }
+
+  function hasVowel(word) {
+    for (var i = 0; i < word.length; i++) {
+      if (!isConsonant(word, i)) {
+        return true;
+      }
+    }
+    return false;
+  }
+
+  function endsWithDoubleConsonant(word) {
+    var n = word.length;
+    return n >= 2 && word[n - 1] === word[n - 2] && isConsonant(word, n - 1);
+  }
+
+  function endsWithCVC(word) {
+    var n = word.length;
+    if (n < 3 || !isConsonant(word, n - 1) || isConsonant(word, n - 2) || !isConsonant(word, n - 3)) {
+      return false;
+    }
+    return "wxy".indexOf(word[n - 1]) === -1;
+  }
+
+  function applyRules(word, rules) {
+    for (var i = 0; i < rules.length; i++) {
+      if (!endsWith(word, rules[i][0])) {
+        continue;
+      }
+      var stem = word.slice(0, word.length - rules[i][0].length);
+      return measure(stem) > 0 ? stem + rules[i][1] : word;
+    }
+    return word;
+  }
+
+  // Porter stemmer, words that are not all lowercase ascii letters are returned unchanged
+  function stem(word) {
+    if (word.length <= 2 || !/^[a-z]+$/.test(word)) {
+      return word;
+    }
+
+    // step 1a, plurals
+    if (endsWith(word, "sses") || endsWith(word, "ies")) {
+      word = word.slice(0, -2);
+    } else if (!endsWith(word, "ss") && endsWith(word, "s")) {
+      word = word.slice(0, -1);
+    }
+
+    // step 1b, past tenses and gerunds
+    if (endsWith(word, "eed")) {
+      if (measure(word.slice(0, -3)) > 0) {
+        word = word.slice(0, -1);
+      }
+    } else {
+      var cut = null;
+      if (endsWith(word, "ed") && hasVowel(word.slice(0, -2))) {
+        cut = word.slice(0, -2);
+      } else if (endsWith(word, "ing") && hasVowel(word.slice(0, -3))) {
+        cut = word.slice(0, -3);
+      }
+      if (cut !== null) {
+        word = cut;
+        if (endsWith(word, "at") || endsWith(word, "bl") || endsWith(word, "iz")) {
+          word += "e";
+        } else if (endsWithDoubleConsonant(word) && "lsz".indexOf(word[word.length - 1]) === -1) {
+          word = word.slice(0, -1);
+        } else if (measure(word) === 1 && endsWithCVC(word)) {
+          word += "e";
+        }
+      }
+    }
+
+    // step 1c
+    if (endsWith(word, "y") && hasVowel(word.slice(0, -1))) {
+      word = word.slice(0, -1) + "i";
+    }
+
+    word = applyRules(word, step2);
+    word = applyRules(word, step3);
+
+    // step 4, removes suffixes of stems that are long enough
+    for (var i = 0; i < step4.length; i++) {
+      var suffix = step4[i];
+      if (!endsWith(word, suffix)) {
+        continue;
+      }
+      var rest = word.slice(0, word.length - suffix.length);
+      if (measure(rest) > 1 && (suffix !== "ion" || endsWith(rest, "s") || endsWith(rest, "t"))) {
+        word = rest;
+      }
+      break;
+    }
+
+    // step 5, a final e and double l
+    if (endsWith(word, "e")) {
+      var withoutE = word.slice(0, -1);
+      var m = measure(withoutE);
+      if (m > 1 || (m === 1 && !endsWithCVC(withoutE))) {
+        word = withoutE;
+      }
+    }
+    if (endsWith(word, "ll") && measure(word) > 1) {
+      word = word.slice(0, -1);
+    }
+
+    return word;
+  }
+
+  // splits text into lowercase words at anything that is not a letter or digit and at camel case boundaries
+  function tokenize(text) {
+    var tokens = [];
+    var current = [];
+    var runes = Array.from(text);
+
+    function flush() {
+      if (current.length !== 0) {
+        tokens.push(current.join("").toLowerCase());
+        current = [];
+      }
+    }
+
+    for (var i = 0; i < runes.length; i++) {
+      var r = runes[i];
+      if (!letterOrDigit.test(r)) {
+        flush();
+        continue;
+      }
+
+      if (upper.test(r) && i > 0 && current.length !== 0) {
+        var previous = runes[i - 1];
+        if (lower.test(previous) || (upper.test(previous) && i + 1 < runes.length && lower.test(runes[i + 1]))) {
+          flush();
+        }
+      }
+
+      current.push(r);
+    }
+    flush();
+
+    return tokens;
+  }
+
+  // tokenizes and stems text, dropping stop words and single characters
+  function terms(text) {
+    return tokenize(text).filter(function (token) {
+      return Array.from(token).length >= 2 && !stopWords[token];
+    }).map(stem);
+  }
+
+  // returns the ids of the addons in the index that contain every term of the query, or null if the query has no terms
+  function match(index, query) {
+    var queryTerms = terms(query);
+    if (queryTerms.length === 0) {
+      return null;
+    }
+
+    var matched = null;
+    queryTerms.forEach(function (term) {
+      var postings = Object.prototype.hasOwnProperty.call(index.terms, term) ? index.terms[term] : [];
+      var positions = {};
+      for (var i = 0; i + 1 < postings.length; i += 2) {
+        if (matched === null || matched[postings[i]]) {
+          positions[postings[i]] = true;
+        }
+      }
+      matched = positions;
+    });
+
+    var ids = {};
+    Object.keys(matched).forEach(function (position) {
+      var doc = index.docs[position];
+      if (doc) {
+        ids[doc[0]] = true;
+      }
+    });
+    return ids;
+  }
+
+  exports.tokenize = tokenize;
+  exports.stem = stem;
+  exports.terms = terms;
+  exports.match = match;
+})(typeof module !== "undefined" ? module.exports : (window.addonSearch = {}));
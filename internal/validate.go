@@ -4,6 +4,7 @@ import (
 	"dev/cqb13/meteor-addon-scanner/scanner"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 )
@@ -23,57 +24,56 @@ type forkedRepository struct {
 	} `json:"parent"`
 }
 
-func ValidateForkedVerifiedAddons(addons []*scanner.Addon) map[string]string {
-	log := make(map[string]string)
-
+// ValidateForkedVerifiedAddons unverifies forked addons that are stale or were forked from an active repo
+func ValidateForkedVerifiedAddons(addons []*scanner.Addon) {
 	for _, addon := range addons {
 		if !addon.Verified || !addon.Repo.Fork {
 			continue
 		}
 
+		logger := slog.With("repo", addon.Repo.Id, "stage", "validate")
+
 		// fetch parent repo
 		url := fmt.Sprintf("https://api.github.com/repos/%s", addon.Repo.Id)
 		bytes, err := scanner.MakeGetRequest(url)
 		if err != nil {
-			log[addon.Repo.Id] = fmt.Sprintf("Failed to check, %s", err)
+			logger.Warn("Failed to check fork", "error", err)
 			continue
 		}
 
 		var forkedRepo forkedRepository
 		err = json.Unmarshal(bytes, &forkedRepo)
 		if err != nil {
-			log[addon.Repo.Id] = fmt.Sprintf("Failed to check, %s", err)
+			logger.Warn("Failed to check fork", "error", err)
 			continue
 		}
 
 		// forks of meteor addon template are valid
 		if strings.ToLower(forkedRepo.Parent.ID) == "meteordevelopment/meteor-addon-template" {
-			log[addon.Repo.Id] = "Parent repo is meteor-addon-template -> Valid"
+			logger.Debug("Parent repo is meteor-addon-template, fork is valid")
 			continue
 		}
 
 		result, err := checkParentAndChildUpdateDates(*addon, &forkedRepo)
 		if err != nil {
-			log[addon.Repo.Id] = fmt.Sprintf("Failed to check, %s", err)
+			logger.Warn("Failed to check fork", "error", err)
 			continue
 		}
 
 		switch result {
 		case valid:
-			log[addon.Repo.Id] = "Valid"
+			logger.Debug("Fork is valid", "parent", forkedRepo.Parent.ID)
 			continue
 		case invalidChildTooOld:
-			log[addon.Repo.Id] = "Repo has not been updated in 6 months -> no longer verified"
+			logger.Info("Repo has not been updated in 6 months, no longer verified", "parent", forkedRepo.Parent.ID)
 			addon.Verified = false
 			continue
 		case invalidParentTooRecent:
-			log[addon.Repo.Id] = "Parent repo was updated within 6 months of the fork -> no longer verified"
+			logger.Info("Parent repo was updated within 6 months of the fork, no longer verified", "parent", forkedRepo.Parent.ID)
 			addon.Verified = false
 			continue
 		}
 	}
-
-	return log
 }
 
 func checkParentAndChildUpdateDates(addon scanner.Addon, forkedRepo *forkedRepository) (ForkValidationResult, error) {
@@ -0,0 +1,51 @@
+package scanner
+
+import (
+	"sort"
+)
+
+// Checkpoint is a snapshot of an unfinished ParseRepos call
+type Checkpoint struct {
+	// repos that have been handled, whether they turned out valid or not
+	Done []string `json:"done"`
+	// repos that still have to be parsed
+	Pending []string `json:"pending"`
+	// valid addons found so far
+	Addons []*Addon `json:"addons"`
+}
+
+func newCheckpoint(repos map[string]struct{}, done map[string]struct{}, addons []*Addon) *Checkpoint {
+	checkpoint := Checkpoint{
+		Done:    make([]string, 0, len(done)),
+		Pending: make([]string, 0, len(repos)-len(done)),
+		Addons:  make([]*Addon, len(addons)),
+	}
+
+	copy(checkpoint.Addons, addons)
+
+	for repo := range repos {
+		if _, ok := done[repo]; ok {
+			checkpoint.Done = append(checkpoint.Done, repo)
+		} else {
+			checkpoint.Pending = append(checkpoint.Pending, repo)
+		}
+	}
+
+	sort.Strings(checkpoint.Done)
+	sort.Strings(checkpoint.Pending)
+
+	return &checkpoint
+}
+
+// Repos returns every repo of the interrupted scan, done and pending
+func (c *Checkpoint) Repos() map[string]struct{} {
+	repos := make(map[string]struct{}, len(c.Done)+len(c.Pending))
+	for _, repo := range c.Done {
+		repos[repo] = struct{}{}
+	}
+	for _, repo := range c.Pending {
+		repos[repo] = struct{}{}
+	}
+
+	return repos
+}
@@ -3,6 +3,7 @@ package scanner
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -71,12 +72,12 @@ func fetchDescriptions(addon *Addon) {
 
 	var response treeResponse
 	if err := json.Unmarshal(searchUrl, &response); err != nil {
-		fmt.Printf("\tFailed to parse %s: %v\n", addon.Name, err)
+		slog.Warn("Failed to parse file tree", "repo", addon.Repo.Id, "stage", "descriptions", "error", err)
 		return
 	}
 
 	if response.Truncated {
-		fmt.Printf("\tWarning: %v tree was truncated by github", addon.Repo.Id)
+		slog.Warn("File tree was truncated by GitHub", "repo", addon.Repo.Id, "stage", "descriptions")
 	}
 
 	featureClasses := make(map[string]string)
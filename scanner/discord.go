@@ -5,27 +5,54 @@ import (
 	"regexp"
 )
 
-func findDiscordServer(fullName string, defaultBranch string, repoStr string, fabricStr string) (string, error) {
+func findDiscordServer(fullName string, defaultBranch string, repoStr string, fabricStr string, t *Trace) (string, error) {
 	url := fmt.Sprintf("https://raw.githubusercontent.com/%v/%v/README.md", fullName, defaultBranch)
-	bytes, err := MakeGetRequest(url)
+	bytes, err := t.get(url)
 	if err != nil {
 		return "", err
 	}
 
 	readme := string(bytes)
 
-	matches := inviteRegex.FindAllString(readme, -1)
-	matches = append(matches, inviteRegex.FindAllString(fabricStr, -1)...)
-	matches = append(matches, inviteRegex.FindAllString(repoStr, -1)...)
+	type candidate struct {
+		invite string
+		source string
+	}
+
+	var matches []candidate
+	for _, invite := range inviteRegex.FindAllString(readme, -1) {
+		matches = append(matches, candidate{invite, "README.md"})
+	}
+	for _, invite := range inviteRegex.FindAllString(fabricStr, -1) {
+		matches = append(matches, candidate{invite, "fabric.mod.json"})
+	}
+	for _, invite := range inviteRegex.FindAllString(repoStr, -1) {
+		matches = append(matches, candidate{invite, "repo metadata"})
+	}
 
-	for _, invite := range matches {
+	if len(matches) == 0 {
+		t.add("discord", "no invite found in README.md, fabric.mod.json or repo metadata")
+	}
+
+	for i, match := range matches {
+		invite := match.invite
 		if !regexp.MustCompile(`^https?://`).MatchString(invite) {
 			invite = "https://" + invite
 		}
 		status, err := MakeHeadRequest(invite)
 		if err == nil && status != 404 {
+			t.add("discord", "picked %s from %s, HEAD returned %d", invite, match.source, status)
+			for _, skipped := range matches[i+1:] {
+				t.add("discord", "ignored %s from %s, an earlier invite was picked", skipped.invite, skipped.source)
+			}
 			return invite, nil
 		}
+
+		if err != nil {
+			t.add("discord", "rejected %s from %s, HEAD failed: %v", invite, match.source, err)
+		} else {
+			t.add("discord", "rejected %s from %s, HEAD returned 404", invite, match.source)
+		}
 	}
 
 	return "", nil
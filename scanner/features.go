@@ -33,10 +33,10 @@ func removeComments(source string) string {
 	return source
 }
 
-func findFeatures(fullName string, defaultBranch string, entrypoint string) (Features, error) {
+func findFeatures(fullName string, defaultBranch string, entrypoint string, t *Trace) (Features, error) {
 	url := fmt.Sprintf("https://raw.githubusercontent.com/%v/%v/src/main/java/%v.java",
 		fullName, defaultBranch, strings.ReplaceAll(entrypoint, ".", "/"))
-	bytes, err := MakeGetRequest(url)
+	bytes, err := t.get(url)
 	if err != nil {
 		return Features{}, err
 	}
@@ -50,6 +50,7 @@ func findFeatures(fullName string, defaultBranch string, entrypoint string) (Fea
 	hudVar := detectVariable(source, `(?m)\bHud\s+(\w+)\s*=\s*(Hud\.get\(\)|Systems\.get\(Hud\.class\));`)
 	systemsVar := detectVariable(source, `(?m)\bSystems\s+(\w+)\s*=\s*Systems\.get\(\);`)
 	tabsVar := detectVariable(source, `(?m)\bTabs\s+(\w+)\s*=\s*Tabs\.get\(\);`)
+	t.add("features", "detected variables: modules '%s', hud '%s', systems '%s', tabs '%s'", moduleVar, hudVar, systemsVar, tabsVar)
 
 	// recognizes added modules
 	modulePattern := `(?m)(Modules\.get\(\)|Systems\.get\(Modules\.class\)|Systems\.add\(`
@@ -91,18 +92,23 @@ func findFeatures(fullName string, defaultBranch string, entrypoint string) (Fea
 	tabRegex := regexp.MustCompile(tabPattern)
 	themeRegex := regexp.MustCompile(themePattern)
 
+	// recognizes module categories, like new Category("Combat", Items.END_CRYSTAL.getDefaultStack())
+	categoryRegex := regexp.MustCompile(`(?m)new\s+Category\s*\(\s*"([^"]+)"`)
+
 	var modules, hudElements, commands []Feature
-	var tabs, themes []string
+	var tabs, themes, categories []string
 	moduleSet := make(map[string]bool)
 	commandSet := make(map[string]bool)
 	hudSet := make(map[string]bool)
 	tabSet := make(map[string]bool)
 	themeSet := make(map[string]bool)
+	categorySet := make(map[string]bool)
 
 	// Extract inline new registrations for modules
 	for _, match := range moduleRegex.FindAllStringSubmatch(source, -1) {
 		if len(match) >= 3 {
 			name := splitCamelCase(match[2])
+			t.add("features", "module '%s' matched the module pattern: %s", name, shorten(match[0]))
 			if !moduleSet[name] {
 				modules = append(modules, Feature{
 					name,
@@ -128,6 +134,7 @@ func findFeatures(fullName string, defaultBranch string, entrypoint string) (Fea
 			}
 
 			name := splitCamelCase(className)
+			t.add("features", "'%s' matched the variable registration pattern: %s", name, shorten(match[0]))
 
 			// Determine which category based on the add call
 			if strings.Contains(registerType, "Module") || strings.Contains(registerType, "System") {
@@ -154,6 +161,7 @@ func findFeatures(fullName string, defaultBranch string, entrypoint string) (Fea
 	for _, match := range hudRegex.FindAllStringSubmatch(source, -1) {
 		if len(match) >= 3 {
 			name := splitCamelCase(match[2])
+			t.add("features", "hud element '%s' matched the hud pattern: %s", name, shorten(match[0]))
 			if !hudSet[name] {
 				hudElements = append(hudElements, Feature{
 					name,
@@ -168,6 +176,7 @@ func findFeatures(fullName string, defaultBranch string, entrypoint string) (Fea
 	for _, match := range commandRegex.FindAllStringSubmatch(source, -1) {
 		if len(match) >= 2 {
 			name := splitCamelCase(match[1])
+			t.add("features", "command '%s' matched the command pattern: %s", name, shorten(match[0]))
 			if !commandSet[name] {
 				commands = append(commands, Feature{
 					name,
@@ -182,6 +191,7 @@ func findFeatures(fullName string, defaultBranch string, entrypoint string) (Fea
 	for _, match := range tabRegex.FindAllStringSubmatch(source, -1) {
 		if len(match) >= 3 {
 			name := splitCamelCase(match[2])
+			t.add("features", "tab '%s' matched the tab pattern: %s", name, shorten(match[0]))
 			if !tabSet[name] {
 				tabs = append(tabs, name)
 				tabSet[name] = true
@@ -193,6 +203,7 @@ func findFeatures(fullName string, defaultBranch string, entrypoint string) (Fea
 	for _, match := range themeRegex.FindAllStringSubmatch(source, -1) {
 		if len(match) >= 3 {
 			name := splitCamelCase(match[2])
+			t.add("features", "theme '%s' matched the theme pattern: %s", name, shorten(match[0]))
 			if !themeSet[name] {
 				themes = append(themes, name)
 				themeSet[name] = true
@@ -200,12 +211,23 @@ func findFeatures(fullName string, defaultBranch string, entrypoint string) (Fea
 		}
 	}
 
+	// Extract categories
+	for _, match := range categoryRegex.FindAllStringSubmatch(source, -1) {
+		name := match[1]
+		t.add("features", "category '%s' matched the category pattern: %s", name, shorten(match[0]))
+		if !categorySet[name] {
+			categories = append(categories, name)
+			categorySet[name] = true
+		}
+	}
+
 	return Features{
 		Modules:      modules,
 		Commands:     commands,
 		HudElements:  hudElements,
 		Tabs:         tabs,
 		Themes:       themes,
+		Categories:   categories,
 		FeatureCount: len(modules) + len(hudElements) + len(commands) + len(tabs),
 	}, nil
 }
@@ -81,9 +81,9 @@ func normalizeMeteorEntrypoints(meteor any) []string {
 	}
 }
 
-func getRepo(fullName string) (*repository, string, error) {
+func getRepo(fullName string, t *Trace) (*repository, string, error) {
 	apiURL := fmt.Sprintf("https://api.github.com/repos/%v", fullName)
-	bytes, err := MakeGetRequest(apiURL)
+	bytes, err := t.get(apiURL)
 	if err != nil {
 		return nil, "", err
 	}
@@ -98,9 +98,9 @@ func getRepo(fullName string) (*repository, string, error) {
 	return &repo, string(bytes), nil
 }
 
-func getFabricModJson(fullName string, defaultBranch string) (*fabric, string, error) {
+func getFabricModJson(fullName string, defaultBranch string, t *Trace) (*fabric, string, error) {
 	rawURL := fmt.Sprintf("https://raw.githubusercontent.com/%v/%v/src/main/resources/fabric.mod.json", fullName, defaultBranch)
-	bytes, err := MakeGetRequest(rawURL)
+	bytes, err := t.get(rawURL)
 	if err != nil {
 		return nil, "", err
 	}
@@ -119,10 +119,10 @@ func getFabricModJson(fullName string, defaultBranch string) (*fabric, string, e
 	return &fabricModJson, string(bytes), nil
 }
 
-func getCustomProperties(fullName string, defaultBranch string, allowedImageHosts []string) (*Custom, error) {
+func getCustomProperties(fullName string, defaultBranch string, allowedImageHosts []string, t *Trace) (*Custom, error) {
 	rawURL := fmt.Sprintf("https://raw.githubusercontent.com/%v/%v/meteor-addon-list.json", fullName, defaultBranch)
 
-	bytes, err := MakeGetRequest(rawURL)
+	bytes, err := t.get(rawURL)
 	if err != nil {
 		return nil, err
 	}
@@ -130,6 +130,7 @@ func getCustomProperties(fullName string, defaultBranch string, allowedImageHost
 	var customData Custom
 
 	if string(bytes) == "404: Not Found" {
+		t.add("custom", "meteor-addon-list.json not found, nothing is overridden")
 		return &customData, nil
 	}
 
@@ -144,6 +145,8 @@ func getCustomProperties(fullName string, defaultBranch string, allowedImageHost
 		v = strings.TrimSpace(v)
 		if mcVersionRegex.MatchString(v) {
 			validVersions = append(validVersions, v)
+		} else {
+			t.add("custom", "dropped supported version '%s', not a Minecraft version", v)
 		}
 	}
 
@@ -161,17 +164,27 @@ func getCustomProperties(fullName string, defaultBranch string, allowedImageHost
 
 		if ok {
 			validTags = append(validTags, realTag)
+		} else {
+			t.add("custom", "dropped tag '%s', not a known tag", tag)
 		}
 	}
 
 	customData.Tags = validTags
 
+	if customData.Discord != "" && sanitizeURL(customData.Discord) == "" {
+		t.add("custom", "dropped discord '%s', not an https url", customData.Discord)
+	}
+	if customData.Homepage != "" && sanitizeURL(customData.Homepage) == "" {
+		t.add("custom", "dropped homepage '%s', not an https url", customData.Homepage)
+	}
+
 	customData.Discord = sanitizeURL(customData.Discord)
 	customData.Homepage = sanitizeURL(customData.Homepage)
 
 	if customData.Icon != "" {
 		parsed, err := url.Parse(customData.Icon)
 		if err != nil || parsed.Scheme != "https" || !slices.Contains(allowedImageHosts, parsed.Host) {
+			t.add("custom", "dropped icon '%s', not an https url on an allowed image host", customData.Icon)
 			customData.Icon = ""
 		}
 	}
@@ -179,16 +192,42 @@ func getCustomProperties(fullName string, defaultBranch string, allowedImageHost
 	return &customData, nil
 }
 
-func getIcon(fullName string, defaultBranch string, icon string) (string, error) {
+func getIcon(fullName string, defaultBranch string, icon string, t *Trace) (string, error) {
 	u := fmt.Sprintf("https://raw.githubusercontent.com/%v/%v/src/main/resources/%v", fullName, defaultBranch, icon)
-	bytes, err := MakeGetRequest(u)
+	bytes, err := t.get(u)
 	if err != nil {
 		return "", err
 	}
 
 	if string(bytes) == "404: Not Found" {
+		t.add("icon", "'%s' from fabric.mod.json not found, no icon", icon)
 		return "", nil
 	}
 
+	t.add("icon", "using '%s' from fabric.mod.json", icon)
+
 	return u, nil
 }
+
+func getHeadSHA(fullName string, defaultBranch string, t *Trace) (string, error) {
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%v/commits/%v", fullName, defaultBranch)
+	bytes, err := t.get(apiURL)
+	if err != nil {
+		return "", err
+	}
+
+	var commit struct {
+		SHA string `json:"sha"`
+	}
+
+	err = json.Unmarshal(bytes, &commit)
+	if err != nil {
+		return "", err
+	}
+
+	if commit.SHA == "" {
+		return "", fmt.Errorf("Could not find HEAD commit of %s", defaultBranch)
+	}
+
+	return commit.SHA, nil
+}
@@ -9,54 +9,66 @@ import (
 var identifierRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 
 // fetches and parses gradle files to extract minecraft version
-func getMinecraftVersion(fullName string, defaultBranch string) string {
+func getMinecraftVersion(fullName string, defaultBranch string, t *Trace) string {
 	// Priority order: gradle/libs.versions.toml → libs.versions.toml → gradle.properties → build.gradle → build.gradle.kts
 
 	// Try gradle/libs.versions.toml first
 	url := fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/gradle/libs.versions.toml", fullName, defaultBranch)
-	if mc, ok := fetchAndParseGradleFile(url); ok {
+	if mc, ok := fetchAndParseGradleFile(url, t); ok {
 		return mc
 	}
 
 	// Try libs.versions.toml in root (some addons put it there)
 	url = fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/libs.versions.toml", fullName, defaultBranch)
-	if mc, ok := fetchAndParseGradleFile(url); ok {
+	if mc, ok := fetchAndParseGradleFile(url, t); ok {
 		return mc
 	}
 
 	// Try gradle.properties
 	url = fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/gradle.properties", fullName, defaultBranch)
-	if mc, ok := fetchAndParseGradleFile(url); ok {
+	if mc, ok := fetchAndParseGradleFile(url, t); ok {
 		return mc
 	}
 
 	// Try build.gradle
 	url = fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/build.gradle", fullName, defaultBranch)
-	if mc, ok := fetchAndParseGradleFile(url); ok {
+	if mc, ok := fetchAndParseGradleFile(url, t); ok {
 		return mc
 	}
 
 	// Try build.gradle.kts
 	url = fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/build.gradle.kts", fullName, defaultBranch)
-	if mc, ok := fetchAndParseGradleFile(url); ok {
+	if mc, ok := fetchAndParseGradleFile(url, t); ok {
 		return mc
 	}
 
+	t.add("gradle", "no minecraft version found in any gradle file")
+
 	return ""
 }
 
-func fetchAndParseGradleFile(url string) (string, bool) {
-	bytes, err := MakeGetRequest(url)
-	if err == nil && string(bytes) != "404: Not Found" {
-		versions := parseGradleVersions(string(bytes))
-		if mc, ok := versions["minecraft_version"]; ok {
-			if mcVersionRegex.MatchString(mc) {
-				return mc, true
-			}
-		}
+func fetchAndParseGradleFile(url string, t *Trace) (string, bool) {
+	bytes, err := t.get(url)
+	if err != nil || string(bytes) == "404: Not Found" {
+		t.add("gradle", "%s not found", url)
+		return "", false
+	}
+
+	versions := parseGradleVersions(string(bytes))
+	mc, ok := versions["minecraft_version"]
+	if !ok {
+		t.add("gradle", "%s has no minecraft version", url)
+		return "", false
+	}
+
+	if !mcVersionRegex.MatchString(mc) {
+		t.add("gradle", "%s has minecraft version '%s' which is not a valid version", url, shorten(mc))
+		return "", false
 	}
 
-	return "", false
+	t.add("gradle", "used minecraft version %s from %s", mc, url)
+
+	return mc, true
 }
 
 // resolves a variable reference like ${var} or properties["var"]
@@ -2,8 +2,10 @@ package scanner
 
 import (
 	"fmt"
+	"log/slog"
 	"strings"
 	"sync"
+	"time"
 )
 
 // determines if an addon is truly just a template with no real content
@@ -35,7 +37,7 @@ func isActualTemplate(features Features) bool {
 }
 
 func findVersion(fullName string, defaultBranch string) (string, error) {
-	minecraftVersion := getMinecraftVersion(fullName, defaultBranch)
+	minecraftVersion := getMinecraftVersion(fullName, defaultBranch, nil)
 
 	if minecraftVersion == "" {
 		return "", fmt.Errorf("Could not find Minecraft version")
@@ -44,25 +46,116 @@ func findVersion(fullName string, defaultBranch string) (string, error) {
 	return minecraftVersion, nil
 }
 
+// returns the homepage of the repo, unless it is a discord invite
+func homepage(repo *repository) string {
+	if inviteRegex.MatchString(repo.Homepage) {
+		return ""
+	}
+
+	return repo.Homepage
+}
+
 func ParseRepo(fullName string, config *Config) (*Addon, error) {
-	repo, repoStr, err := getRepo(fullName)
+	addon, _, err := parseRepo(fullName, config, nil, nil)
+	return addon, err
+}
+
+// TraceRepo parses the repo like ParseRepo, recording every request made and
+// where each field of the addon came from in trace
+func TraceRepo(fullName string, config *Config, trace *Trace) (*Addon, error) {
+	addon, _, err := parseRepo(fullName, config, nil, trace)
+	return addon, err
+}
+
+// records which values of meteor-addon-list.json replace or add to the scanned ones
+func traceOverrides(t *Trace, addon *Addon) {
+	custom := addon.Custom
+
+	override := func(field string, scanned string, value string) {
+		if value == "" {
+			return
+		}
+
+		if scanned == "" {
+			t.add("custom", "%s set to '%s'", field, shorten(value))
+		} else {
+			t.add("custom", "%s '%s' overrides scanned '%s'", field, shorten(value), shorten(scanned))
+		}
+	}
+
+	override("description", addon.Description, custom.Description)
+	override("icon", addon.Links.Icon, custom.Icon)
+	override("discord", addon.Links.Discord, custom.Discord)
+	override("homepage", addon.Links.Homepage, custom.Homepage)
+
+	if len(custom.SupportedVersions) != 0 {
+		t.add("custom", "supported versions %v are used alongside scanned version '%s'", custom.SupportedVersions, addon.McVersion)
+	}
+	if len(custom.Tags) != 0 {
+		t.add("custom", "tags %v", custom.Tags)
+	}
+}
+
+// parseRepo parses the repo, when a state is given and neither the HEAD commit nor the latest release
+// changed since the previous scan the cached addon is reused with only the cheap fields refreshed.
+// Returns the addon and the new state of the repo, without the addon attached
+func parseRepo(fullName string, config *Config, state *State, t *Trace) (*Addon, *RepoState, error) {
+	repo, repoStr, err := getRepo(fullName, t)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	newState := &RepoState{
+		PushedAt:        repo.PushedAt,
+		DefaultBranch:   repo.DefaultBranch,
+		MetadataInvites: inviteRegex.FindAllString(repoStr, -1),
+	}
+
+	var releases *releaseDetails
+	if state != nil {
+		previous := state.Get(fullName)
+
+		// nothing can have been committed if the repo was not pushed to
+		if previous != nil && previous.PushedAt == repo.PushedAt && previous.DefaultBranch == repo.DefaultBranch {
+			newState.HeadSHA = previous.HeadSHA
+		} else {
+			newState.HeadSHA, err = getHeadSHA(fullName, repo.DefaultBranch, t)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if previous != nil && previous.HeadSHA == newState.HeadSHA {
+			releases, err = getReleaseDetails(fullName, t)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if previous.unchanged(newState.HeadSHA, releases.LatestId, newState.MetadataInvites) {
+				newState.ReleaseId = releases.LatestId
+				newState.Entrypoint = previous.Entrypoint
+				newState.Descriptions = previous.Descriptions
+				newState.FabricDescription = previous.FabricDescription
+				t.add("state", "HEAD %s, latest release and discord invites are unchanged, reusing the cached addon", newState.HeadSHA)
+				return previous.refresh(repo, releases), newState, nil
+			}
+		}
 	}
 
-	fabricModJson, fabricStr, err := getFabricModJson(fullName, repo.DefaultBranch)
+	fabricModJson, fabricStr, err := getFabricModJson(fullName, repo.DefaultBranch, t)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	meteorEntries := normalizeMeteorEntrypoints(fabricModJson.Entrypoints.Meteor)
 	if len(meteorEntries) == 0 {
-		return nil, fmt.Errorf("No meteor entrypoint found in fabric.mod.json")
+		return nil, nil, fmt.Errorf("No meteor entrypoint found in fabric.mod.json")
 	}
 
 	description := repo.Description
 	if description == "" {
 		description = fabricModJson.Description
+		t.add("description", "repo has no description, using the one from fabric.mod.json")
 	}
 
 	// find authors from fabric.mod.json or from github username
@@ -71,49 +164,50 @@ func ParseRepo(fullName string, config *Config) (*Addon, error) {
 		authors = append(authors, repo.Owner.Login)
 	}
 
-	downloads, latestRelease, downloadCount, err := getReleaseDetails(fullName)
-	if err != nil {
-		return nil, err
+	if releases == nil {
+		releases, err = getReleaseDetails(fullName, t)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
+	newState.ReleaseId = releases.LatestId
 
-	icon, err := getIcon(fullName, repo.DefaultBranch, fabricModJson.Icon)
+	icon, err := getIcon(fullName, repo.DefaultBranch, fabricModJson.Icon, t)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	invite, err := findDiscordServer(fullName, repo.DefaultBranch, repoStr, fabricStr)
+	invite, err := findDiscordServer(fullName, repo.DefaultBranch, repoStr, fabricStr, t)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	features, err := findFeatures(fullName, repo.DefaultBranch, meteorEntries[0])
+	features, err := findFeatures(fullName, repo.DefaultBranch, meteorEntries[0], t)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Template detection, but allow actual addon template
 	if fabricModJson.Id == "addon-template" && isActualTemplate(features) && strings.ToLower(repo.FullName) != "meteordevelopment/meteor-addon-template" {
-		return nil, nil
+		t.add("template", "fabric.mod.json id is addon-template and every feature is an example, skipping")
+		return nil, nil, nil
 	}
 
-	version := getMinecraftVersion(fullName, repo.DefaultBranch)
+	version := getMinecraftVersion(fullName, repo.DefaultBranch, t)
 
-	site := repo.Homepage
-
-	// prevent the homepage being a discord invite
-	if inviteRegex.MatchString(site) {
-		site = ""
-	}
-
-	customProperties, err := getCustomProperties(fullName, repo.DefaultBranch, config.AllowedImageHosts)
+	customProperties, err := getCustomProperties(fullName, repo.DefaultBranch, config.AllowedImageHosts, t)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if version == "" && len(customProperties.SupportedVersions) == 0 && config.RequireMinecraftVersion {
-		return nil, fmt.Errorf("Could not find Minecraft version")
+		return nil, nil, fmt.Errorf("Could not find Minecraft version")
 	}
 
+	entrypoint := strings.ReplaceAll(meteorEntries[0], ".", "/")
+	newState.Entrypoint = entrypoint
+	newState.FabricDescription = fabricModJson.Description
+
 	addon := Addon{
 		Name:        fabricModJson.Name,
 		Description: description,
@@ -121,7 +215,7 @@ func ParseRepo(fullName string, config *Config) (*Addon, error) {
 		Authors:     authors,
 		Features:    features,
 		Verified:    false,
-		entrypoint:  strings.ReplaceAll(meteorEntries[0], ".", "/"),
+		entrypoint:  entrypoint,
 		Repo: Repo{
 			Id:            fullName,
 			defaultBranch: repo.DefaultBranch,
@@ -131,39 +225,105 @@ func ParseRepo(fullName string, config *Config) (*Addon, error) {
 			Fork:          repo.Fork,
 			Forks:         repo.Forks,
 			Stars:         repo.Stars,
-			Downloads:     downloadCount,
+			Downloads:     releases.DownloadCount,
 			LastUpdate:    repo.PushedAt,
 			CreationDate:  repo.CreatedAt,
+			Topics:        repo.Topics,
 		},
 		Links: Links{
 			Github:        sanitizeURL(repo.HtmlUrl),
-			Downloads:     downloads,
-			LatestRelease: sanitizeURL(latestRelease),
+			Downloads:     releases.Downloads,
+			Prereleases:   releases.Prereleases,
+			LatestRelease: sanitizeURL(releases.LatestRelease),
 			Discord:       sanitizeURL(invite),
 			Icon:          icon,
-			Homepage:      sanitizeURL(site),
+			Homepage:      sanitizeURL(homepage(repo)),
 		},
 		Custom: *customProperties,
 	}
 
-	return &addon, nil
+	if t != nil {
+		traceOverrides(t, &addon)
+	}
+
+	return &addon, newState, nil
+}
+
+// pushedSince reports whether the repo has been pushed to after the given time
+func pushedSince(fullName string, since time.Time) (bool, error) {
+	repo, _, err := getRepo(fullName, nil)
+	if err != nil {
+		return false, err
+	}
+
+	pushedAt, err := time.Parse(time.RFC3339, repo.PushedAt)
+	if err != nil {
+		return false, fmt.Errorf("Invalid pushed_at '%s': %v", repo.PushedAt, err)
+	}
+
+	return pushedAt.After(since), nil
+}
+
+// clears fetched feature descriptions, used when a cached addon no longer qualifies for them
+func clearDescriptions(features []Feature) {
+	for i := range features {
+		features[i].Description = ""
+	}
+}
+
+// ParseOptions configures the stores and hooks used by ParseRepos
+type ParseOptions struct {
+	// records failed repos and decides when they are retried, required
+	RetryLog *RetryLog
+	// lets repos that did not change since the previous scan be skipped, optional
+	State *State
+	// checkpoint of an interrupted scan, repos it marks as done are not parsed again, optional
+	Resume *Checkpoint
+	// called every CheckpointInterval with a snapshot of the scan, optional
+	OnCheckpoint       func(*Checkpoint)
+	CheckpointInterval time.Duration
+	// counts the repos that are done, optional
+	Progress *Progress
+	// called with every addon as soon as it is parsed, from many goroutines at once, optional
+	OnAddon func(*Addon)
 }
 
-func ParseRepos(repos map[string]struct{}, config *Config, invalidAddonsLog map[string]any) []*Addon {
+// ParseRepos parses all repos concurrently
+func ParseRepos(repos map[string]struct{}, config *Config, options ParseOptions) []*Addon {
 	verifiedSet := make(map[string]bool)
 	for _, repo := range config.VerifiedAddons.Verified {
 		verifiedSet[strings.ToLower(repo)] = true
 	}
 
-	var addons []*Addon
-	var addonsMutex sync.Mutex
+	retryLog := options.RetryLog
+	state := options.State
 
-	var invalidAddonsLogMutex sync.Mutex
+	var addons []*Addon
 	var wg sync.WaitGroup
 
+	// guards addons and done
+	var mu sync.Mutex
+	done := make(map[string]struct{})
+
+	if options.Resume != nil {
+		addons = append(addons, options.Resume.Addons...)
+		for _, repo := range options.Resume.Done {
+			done[repo] = struct{}{}
+		}
+		slog.Info("Resuming from checkpoint", "stage", "parse", "done", len(done), "repos", len(repos))
+	}
+
+	progress := options.Progress
+	progress.start(len(repos), len(done))
+
 	semaphore := make(chan struct{}, 10)
+	startTime := time.Now()
 
 	for repo := range repos {
+		if _, ok := done[repo]; ok {
+			continue
+		}
+
 		wg.Add(1)
 
 		go func(repoName string) {
@@ -172,40 +332,114 @@ func ParseRepos(repos map[string]struct{}, config *Config, invalidAddonsLog map[
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			_, ok := invalidAddonsLog[repoName]
-			if ok {
-				fmt.Printf("\tSkipping %s: Marked as invalid\n", repoName)
-				return
+			// repos without an addon are done once they are skipped or failed
+			defer func() {
+				mu.Lock()
+				done[repoName] = struct{}{}
+				mu.Unlock()
+			}()
+
+			if entry, ok := retryLog.Get(repoName); ok && startTime.Before(entry.NextAttempt()) {
+				// when the check fails the repo is parsed, so the failure is recorded instead of the repo being skipped again
+				pushed, err := pushedSince(repoName, entry.LastFailure)
+				if err != nil {
+					slog.Warn("Failed to check if repo was pushed to, rescanning", "repo", repoName, "stage", "parse", "error", err)
+				} else if !pushed {
+					slog.Info("Skipping repo that failed before", "repo", repoName, "stage", "parse", "attempts", entry.Attempts, "retry_after", entry.NextAttempt().Format(time.DateOnly))
+					progress.record(outcomeSkipped)
+					return
+				} else {
+					slog.Info("Rescanning repo pushed since its last failure", "repo", repoName, "stage", "parse")
+				}
 			}
 
-			addon, err := ParseRepo(repoName, config)
+			addon, repoState, err := parseRepo(repoName, config, state, nil)
 			if err != nil {
-				invalidAddonsLogMutex.Lock()
-				invalidAddonsLog[repoName] = nil
-				invalidAddonsLogMutex.Unlock()
+				retryLog.RecordFailure(repoName, err, startTime)
+				if !IsTransient(err) {
+					state.Forget(repoName)
+				}
 
-				fmt.Printf("\tFailed to parse %s: %v\n", repoName, err)
+				slog.Warn("Failed to parse repo", "repo", repoName, "stage", "parse", "transient", IsTransient(err), "error", err)
+				progress.record(outcomeInvalid)
 				return
 			}
 
+			retryLog.RecordSuccess(repoName)
+
 			if addon == nil {
-				fmt.Printf("\tSkipped template: %s\n", repoName)
+				state.Forget(repoName)
+				slog.Info("Skipped template", "repo", repoName, "stage", "parse")
+				progress.record(outcomeInvalid)
 				return
 			}
 
+			addon.LastScanned = time.Now().UTC().Format(time.RFC3339)
+
 			addon.Verified = verifiedSet[strings.ToLower(repoName)]
 
 			if config.ModuleDescriptions.Fetch && (config.ModuleDescriptions.OnlyVerified && addon.Verified || !config.ModuleDescriptions.OnlyVerified) && addon.Repo.Stars >= config.ModuleDescriptions.MinStarCount {
-				fetchDescriptions(addon)
+				if !repoState.Descriptions {
+					fetchDescriptions(addon)
+					repoState.Descriptions = true
+				}
+			} else if repoState.Descriptions {
+				clearDescriptions(addon.Features.Modules)
+				clearDescriptions(addon.Features.Commands)
+				clearDescriptions(addon.Features.HudElements)
+				repoState.Descriptions = false
 			}
 
-			addonsMutex.Lock()
+			cached := *addon
+			repoState.Addon = &cached
+			state.Set(repoName, repoState)
+
+			slog.Debug("Parsed repo", "repo", repoName, "stage", "parse", "features", addon.Features.FeatureCount)
+
+			// marked done together with the append, so a checkpoint never holds the addon while its repo is still pending
+			mu.Lock()
 			addons = append(addons, addon)
-			addonsMutex.Unlock()
+			done[repoName] = struct{}{}
+			mu.Unlock()
+
+			if options.OnAddon != nil {
+				options.OnAddon(addon)
+			}
+
+			progress.record(outcomeValid)
 		}(repo)
 	}
 
+	// periodically hand out snapshots so an interrupted scan can be resumed
+	stopCheckpoints := make(chan struct{})
+	checkpointsStopped := make(chan struct{})
+	go func() {
+		defer close(checkpointsStopped)
+
+		if options.OnCheckpoint == nil || options.CheckpointInterval <= 0 {
+			return
+		}
+
+		ticker := time.NewTicker(options.CheckpointInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				mu.Lock()
+				checkpoint := newCheckpoint(repos, done, addons)
+				mu.Unlock()
+
+				options.OnCheckpoint(checkpoint)
+			case <-stopCheckpoints:
+				return
+			}
+		}
+	}()
+
 	wg.Wait()
+	close(stopCheckpoints)
+	<-checkpointsStopped
 
 	return addons
 }
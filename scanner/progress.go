@@ -0,0 +1,135 @@
+package scanner
+
+import (
+	"sync"
+	"time"
+)
+
+// Progress tracks how far ParseRepos got, it is safe for concurrent use.
+// A nil progress tracks nothing
+type Progress struct {
+	mu        sync.Mutex
+	total     int
+	resumed   int
+	valid     int
+	invalid   int
+	skipped   int
+	startedAt time.Time
+}
+
+// ProgressSnapshot is the state of a scan at one point in time
+type ProgressSnapshot struct {
+	Total   int
+	Done    int
+	Valid   int
+	Invalid int
+	Skipped int
+	Elapsed time.Duration
+	// estimated time until every repo is done, 0 until the first repo of this run is done
+	ETA    time.Duration
+	Search RateLimitTracker
+	Core   RateLimitTracker
+	// why requests are currently paused, empty if they are not
+	Sleeping      string
+	SleepingUntil time.Time
+}
+
+func NewProgress() *Progress {
+	return &Progress{}
+}
+
+// start resets the progress, resumed repos count as done but not towards the ETA
+func (p *Progress) start(total int, resumed int) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.total = total
+	p.resumed = resumed
+	p.valid, p.invalid, p.skipped = 0, 0, 0
+	p.startedAt = time.Now()
+}
+
+type outcome int
+
+const (
+	outcomeValid outcome = iota
+	outcomeInvalid
+	outcomeSkipped
+)
+
+// record counts a repo as done with the given outcome
+func (p *Progress) record(result outcome) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch result {
+	case outcomeValid:
+		p.valid++
+	case outcomeInvalid:
+		p.invalid++
+	case outcomeSkipped:
+		p.skipped++
+	}
+}
+
+func (p *Progress) Snapshot() ProgressSnapshot {
+	p.mu.Lock()
+	snapshot := ProgressSnapshot{
+		Total:   p.total,
+		Valid:   p.valid,
+		Invalid: p.invalid,
+		Skipped: p.skipped,
+		Elapsed: time.Since(p.startedAt),
+	}
+	doneThisRun := p.valid + p.invalid + p.skipped
+	snapshot.Done = p.resumed + doneThisRun
+	p.mu.Unlock()
+
+	if doneThisRun != 0 && snapshot.Done < snapshot.Total {
+		perRepo := snapshot.Elapsed / time.Duration(doneThisRun)
+		snapshot.ETA = perRepo * time.Duration(snapshot.Total-snapshot.Done)
+	}
+
+	snapshot.Search, snapshot.Core = RateLimits()
+	snapshot.Sleeping, snapshot.SleepingUntil = sleepStatus()
+
+	return snapshot
+}
+
+var sleeping struct {
+	reason string
+	until  time.Time
+	mu     sync.Mutex
+}
+
+// sleep pauses for d and records why, so progress reports can show it
+func sleep(reason string, d time.Duration) {
+	sleeping.mu.Lock()
+	sleeping.reason = reason
+	sleeping.until = time.Now().Add(d)
+	sleeping.mu.Unlock()
+
+	time.Sleep(d)
+
+	sleeping.mu.Lock()
+	if !time.Now().Before(sleeping.until) {
+		sleeping.reason = ""
+		sleeping.until = time.Time{}
+	}
+	sleeping.mu.Unlock()
+}
+
+func sleepStatus() (string, time.Time) {
+	sleeping.mu.Lock()
+	defer sleeping.mu.Unlock()
+
+	return sleeping.reason, sleeping.until
+}
@@ -31,7 +31,8 @@ func isValidJarAsset(assetName string) bool {
 	return true
 }
 
-func extractMCVersionFromFilename(filename string) string {
+// ExtractMCVersionFromFilename returns the Minecraft version in the name of a release asset, or "" if there is none
+func ExtractMCVersionFromFilename(filename string) string {
 	// Match patterns like: 1.21, 1.21.1, 1.21.10, 26.1, 26.1.0, etc.
 	re := regexp.MustCompile(`(?i)(?:^|[_\-\.])(?:mc)?[_\-\.]?((?:1\.\d+|\d{2}\.\d+)(?:\.\d+)?)(?:[_\-\.]|\.jar$)`)
 	matches := re.FindStringSubmatch(filename)
@@ -41,7 +42,7 @@ func extractMCVersionFromFilename(filename string) string {
 	return ""
 }
 
-func getReleaseDetails(fullName string) ([]string, string, int, error) {
+func getReleaseDetails(fullName string, t *Trace) (*releaseDetails, error) {
 	url := fmt.Sprintf("https://api.github.com/repos/%v/releases?per_page=100&page=", fullName)
 
 	var stableDownloads []string
@@ -49,20 +50,21 @@ func getReleaseDetails(fullName string) ([]string, string, int, error) {
 	var latestDownload string
 	var latestVersion string
 	var totalDownloadCount int
+	var latestId int64
 	foundStable := false
 	foundPrerelease := false
 	page := 1
 
 	for {
-		bytes, err := MakeGetRequest(fmt.Sprintf("%v%v", url, page))
+		bytes, err := t.get(fmt.Sprintf("%v%v", url, page))
 		if err != nil {
-			return nil, "", 0, err
+			return nil, err
 		}
 
 		var releases []release
 		err = json.Unmarshal(bytes, &releases)
 		if err != nil {
-			return nil, "", 0, err
+			return nil, err
 		}
 
 		if len(releases) == 0 {
@@ -74,6 +76,10 @@ func getReleaseDetails(fullName string) ([]string, string, int, error) {
 				continue
 			}
 
+			if latestId == 0 {
+				latestId = rel.Id
+			}
+
 			isStable := !rel.Prerelease
 
 			for _, asset := range rel.Assets {
@@ -91,7 +97,7 @@ func getReleaseDetails(fullName string) ([]string, string, int, error) {
 					stableDownloads = append(stableDownloads, asset.Url)
 
 					// Extract MC version from filename and track highest version
-					mcVersion := extractMCVersionFromFilename(asset.Name)
+					mcVersion := ExtractMCVersionFromFilename(asset.Name)
 
 					if latestVersion == "" || CompareMinecraftVersions(mcVersion, latestVersion) > 0 {
 						latestVersion = mcVersion
@@ -116,7 +122,7 @@ func getReleaseDetails(fullName string) ([]string, string, int, error) {
 					prereleaseDownloads = append(prereleaseDownloads, asset.Url)
 
 					// Extract MC version from filename and track highest version
-					mcVersion := extractMCVersionFromFilename(asset.Name)
+					mcVersion := ExtractMCVersionFromFilename(asset.Name)
 					if latestVersion == "" || CompareMinecraftVersions(mcVersion, latestVersion) > 0 {
 						latestVersion = mcVersion
 						latestDownload = asset.Url
@@ -138,8 +144,19 @@ func getReleaseDetails(fullName string) ([]string, string, int, error) {
 	allDownloads := append(stableDownloads, prereleaseDownloads...)
 
 	if len(allDownloads) == 0 {
-		return []string{}, "", totalDownloadCount, nil
+		allDownloads = []string{}
 	}
+	if len(prereleaseDownloads) == 0 {
+		prereleaseDownloads = []string{}
+	}
+
+	t.add("releases", "found %d downloads, %d total download count, latest release '%s'", len(allDownloads), totalDownloadCount, latestDownload)
 
-	return allDownloads, latestDownload, totalDownloadCount, nil
+	return &releaseDetails{
+		Downloads:     allDownloads,
+		Prereleases:   prereleaseDownloads,
+		LatestRelease: latestDownload,
+		DownloadCount: totalDownloadCount,
+		LatestId:      latestId,
+	}, nil
 }
@@ -0,0 +1,128 @@
+package scanner
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+type FailureClass string
+
+const (
+	// Transient failures (timeouts, rate limits, server errors) are retried on the next scan
+	Transient FailureClass = "transient"
+	// Permanent failures (missing fabric.mod.json, no entrypoint, ...) are backed off exponentially
+	Permanent FailureClass = "permanent"
+)
+
+// the first retry of a permanently failing repo happens after a day,
+// every following failure doubles the wait up to a month
+const retryBaseDelay = 24 * time.Hour
+const retryMaxDelay = 30 * 24 * time.Hour
+
+type RetryEntry struct {
+	Class        FailureClass `json:"class"`
+	Reason       string       `json:"reason"`
+	FirstFailure time.Time    `json:"first_failure"`
+	LastFailure  time.Time    `json:"last_failure"`
+	Attempts     int          `json:"attempts"`
+}
+
+// NextAttempt returns the earliest time the repo should be scanned again
+func (e *RetryEntry) NextAttempt() time.Time {
+	if e.Class == Transient {
+		return e.LastFailure
+	}
+
+	delay := retryBaseDelay
+	for i := 1; i < e.Attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+
+	return e.LastFailure.Add(min(delay, retryMaxDelay))
+}
+
+// RetryLog keeps track of repos that failed to parse and when they should be retried
+type RetryLog struct {
+	Entries map[string]*RetryEntry
+	mu      sync.Mutex
+}
+
+func NewRetryLog() *RetryLog {
+	return &RetryLog{Entries: make(map[string]*RetryEntry)}
+}
+
+func (l *RetryLog) MarshalJSON() ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return json.Marshal(l.Entries)
+}
+
+func (l *RetryLog) UnmarshalJSON(data []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return json.Unmarshal(data, &l.Entries)
+}
+
+// Get returns a copy of the entry for the repo, if it has failed before
+func (l *RetryLog) Get(repo string) (RetryEntry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.Entries[repo]
+	if !ok {
+		return RetryEntry{}, false
+	}
+
+	return *entry, true
+}
+
+// RecordFailure classifies err and updates the entry for the repo
+func (l *RetryLog) RecordFailure(repo string, err error, now time.Time) {
+	class := Permanent
+	if IsTransient(err) {
+		class = Transient
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.Entries[repo]
+	if !ok {
+		entry = &RetryEntry{FirstFailure: now}
+		l.Entries[repo] = entry
+	}
+
+	entry.Class = class
+	entry.Reason = err.Error()
+	entry.LastFailure = now
+	entry.Attempts++
+}
+
+// RecordSuccess forgets any previous failures of the repo
+func (l *RetryLog) RecordSuccess(repo string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.Entries, repo)
+}
+
+// Failures returns the sorted repos of the given set whose last failure has the given class
+func (l *RetryLog) Failures(repos map[string]struct{}, class FailureClass) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	failures := make([]string, 0)
+	for repo := range repos {
+		if entry, ok := l.Entries[repo]; ok && entry.Class == class {
+			failures = append(failures, repo)
+		}
+	}
+
+	sort.Strings(failures)
+
+	return failures
+}
@@ -2,8 +2,9 @@ package scanner
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"log/slog"
 	"strings"
 	"time"
 )
@@ -19,28 +20,32 @@ var repos = make(map[string]struct{})
 
 const reposPerPage int = 100
 
-func fetchBySearch(name string, url string) {
+func fetchBySearch(name string, url string) error {
 	attempts := RetryAttempts
+	var lastErr error
 
 	page := 1
-	fmt.Printf("\tFetching based on %v\n", name)
+	slog.Info("Fetching search results", "stage", "locate", "source", name)
 	for {
 		if attempts == 0 {
-			fmt.Printf("Failed to make search request\n")
-			os.Exit(1)
+			return fmt.Errorf("Failed to make search request for %v: %w", name, lastErr)
 		}
 
-		fmt.Printf("\t\tFetching Page %v -> ", page)
 		bytes, err := MakeGetRequest(fmt.Sprintf("%s%v", url, page))
 		if err != nil {
-			fmt.Printf("Error: %v (attempt %d/%d)\n", err, RetryAttempts-attempts+1, RetryAttempts)
+			if errors.Is(err, ErrUnauthorized) {
+				return err
+			}
+
+			slog.Warn("Failed to fetch page", "stage", "locate", "source", name, "page", page, "attempt", RetryAttempts-attempts+1, "of", RetryAttempts, "error", err)
+			lastErr = err
 			attempts -= 1
 			continue
 		}
 
 		if strings.HasSuffix(string(bytes), "\"status\":\"403\"}") {
-			fmt.Printf("Rate Limited -> Sleeping for 60 seconds...\n")
-			time.Sleep(60 * time.Second)
+			slog.Warn("Rate limited, sleeping for 60 seconds", "stage", "locate", "source", name, "page", page)
+			sleep("secondary rate limit", 60*time.Second)
 			continue
 		}
 
@@ -62,13 +67,12 @@ func fetchBySearch(name string, url string) {
 
 		err = json.Unmarshal(bytes, &result)
 		if err != nil {
-			fmt.Printf("Failed to parse JSON\n")
-			os.Exit(1)
+			return fmt.Errorf("Failed to parse search results for %v: %w", name, err)
 		}
 
 		var reposOnPage int = len(result.Items)
 
-		fmt.Printf("Found %v Repositories\n", reposOnPage)
+		slog.Debug("Fetched page", "stage", "locate", "source", name, "page", page, "repos", reposOnPage)
 
 		for _, item := range result.Items {
 			var full string
@@ -105,35 +109,41 @@ func fetchBySearch(name string, url string) {
 		page += 1
 
 		if page > 10 {
-			fmt.Printf("\t\tFetching over ten pages -> stoping the scanning for %v", name)
+			slog.Info("Stopped after ten pages", "stage", "locate", "source", name)
 			break
 		}
 	}
+
+	return nil
 }
 
 // Fetch all repos that are forks of the template
-func fetchByForkOfTemplate() {
+func fetchByForkOfTemplate() error {
 	attempts := RetryAttempts
+	var lastErr error
 	url := fmt.Sprintf("https://api.github.com/repos/MeteorDevelopment/meteor-addon-template/forks?per_page=%v&page=", reposPerPage)
 
 	page := 1
-	fmt.Printf("\tFetching fokrs of template\n")
+	slog.Info("Fetching forks of template", "stage", "locate", "source", "forks")
 	for {
 		if attempts == 0 {
-			fmt.Printf("Failed to make search request\n")
-			os.Exit(1)
+			return fmt.Errorf("Failed to fetch forks of template: %w", lastErr)
 		}
-		fmt.Printf("\t\tFetching Page %v -> ", page)
 		bytes, err := MakeGetRequest(fmt.Sprintf("%s%v", url, page))
 		if err != nil {
-			fmt.Printf("Error: %v (attempt %d/%d)\n", err, RetryAttempts-attempts+1, RetryAttempts)
+			if errors.Is(err, ErrUnauthorized) {
+				return err
+			}
+
+			slog.Warn("Failed to fetch page", "stage", "locate", "source", "forks", "page", page, "attempt", RetryAttempts-attempts+1, "of", RetryAttempts, "error", err)
+			lastErr = err
 			attempts -= 1
 			continue
 		}
 
 		if strings.HasSuffix(string(bytes), "\"status\":\"403\"}") {
-			fmt.Printf("Rate Limited -> Sleeping for 60 seconds...\n")
-			time.Sleep(60 * time.Second)
+			slog.Warn("Rate limited, sleeping for 60 seconds", "stage", "locate", "source", "forks", "page", page)
+			sleep("secondary rate limit", 60*time.Second)
 			continue
 		}
 
@@ -146,13 +156,12 @@ func fetchByForkOfTemplate() {
 
 		err = json.Unmarshal(bytes, &result)
 		if err != nil {
-			fmt.Printf("Failed to parse JSON\n")
-			os.Exit(1)
+			return fmt.Errorf("Failed to parse forks of template: %w", err)
 		}
 
 		reposOnPage := len(result)
 
-		fmt.Printf("Found %v Repositories\n", reposOnPage)
+		slog.Debug("Fetched page", "stage", "locate", "source", "forks", "page", page, "repos", reposOnPage)
 
 		for _, repo := range result {
 			_, ok := repos[strings.ToLower(repo.FullName)]
@@ -169,32 +178,41 @@ func fetchByForkOfTemplate() {
 		page += 1
 
 		if page > 10 {
-			fmt.Println("\t\tFetching over ten pages -> stoping the scanning for forks of template")
+			slog.Info("Stopped after ten pages", "stage", "locate", "source", "forks")
 			break
 		}
 	}
+
+	return nil
 }
 
-func Locate(verifiedAddons []string) map[string]struct{} {
+// searches that locate addon repositories, by name and url without the page number
+var searches = []struct {
+	name string
+	url  string
+}{
+	{"fabric.mod.json", fmt.Sprintf("https://api.github.com/search/code?q=entrypoints+meteor+extension:json+filename:fabric.mod.json+fork:true+in:file&per_page=%v&page=", reposPerPage)},
+	{"Extend MeteorAddon", fmt.Sprintf("https://api.github.com/search/code?q=extends+MeteorAddon+language:java+in:file&per_page=%v&page=", reposPerPage)},
+	{"meteor-addon topic", fmt.Sprintf("https://api.github.com/search/repositories?q=topic:meteor-addon&per_page=%d&page=", reposPerPage)},
+	{"meteor-client-addon topic", fmt.Sprintf("https://api.github.com/search/repositories?q=topic:meteor-client-addon&per_page=%d&page=", reposPerPage)},
+	{"meteor-addon in name or description", fmt.Sprintf("https://api.github.com/search/repositories?q=meteor-addon+in:name,description&per_page=%d&page=", reposPerPage)},
+	{"meteor-client addon in description", fmt.Sprintf("https://api.github.com/search/repositories?q=meteor-client+addon+in:description&per_page=%d&page=", reposPerPage)},
+}
+
+func Locate(verifiedAddons []string) (map[string]struct{}, error) {
 	for _, addon := range verifiedAddons {
 		repos[strings.ToLower(addon)] = struct{}{}
 	}
 
-	url := fmt.Sprintf("https://api.github.com/search/code?q=entrypoints+meteor+extension:json+filename:fabric.mod.json+fork:true+in:file&per_page=%v&page=", reposPerPage)
-	fetchBySearch("fabric.mod.json", url)
-	url = fmt.Sprintf("https://api.github.com/search/code?q=extends+MeteorAddon+language:java+in:file&per_page=%v&page=", reposPerPage)
-	fetchBySearch("Extend MeteorAddon", url)
-
-	url = fmt.Sprintf("https://api.github.com/search/repositories?q=topic:meteor-addon&per_page=%d&page=", reposPerPage)
-	fetchBySearch("meteor-addon topic", url)
-	url = fmt.Sprintf("https://api.github.com/search/repositories?q=topic:meteor-client-addon&per_page=%d&page=", reposPerPage)
-	fetchBySearch("meteor-client-addon topic", url)
-	url = fmt.Sprintf("https://api.github.com/search/repositories?q=meteor-addon+in:name,description&per_page=%d&page=", reposPerPage)
-	fetchBySearch("meteor-addon in name or description", url)
-	url = fmt.Sprintf("https://api.github.com/search/repositories?q=meteor-client+addon+in:description&per_page=%d&page=", reposPerPage)
-	fetchBySearch("meteor-client addon in description", url)
+	for _, search := range searches {
+		if err := fetchBySearch(search.name, search.url); err != nil {
+			return nil, err
+		}
+	}
 
-	fetchByForkOfTemplate()
+	if err := fetchByForkOfTemplate(); err != nil {
+		return nil, err
+	}
 
-	return repos
+	return repos, nil
 }
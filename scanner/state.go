@@ -0,0 +1,151 @@
+package scanner
+
+import (
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// StateVersion is bumped whenever the parser changes what it produces,
+// cached addons of a state with another version are dropped so every repo is parsed again.
+// 2 added the categories of features
+const StateVersion = 2
+
+// RepoState is what the previous scan learned about a valid addon repo
+type RepoState struct {
+	HeadSHA       string `json:"head_sha"`
+	PushedAt      string `json:"pushed_at"`
+	ReleaseId     int64  `json:"release_id"`
+	DefaultBranch string `json:"default_branch"`
+	Entrypoint    string `json:"entrypoint"`
+	Descriptions  bool   `json:"descriptions"`
+	// description from fabric.mod.json, used when the repo has none
+	FabricDescription string `json:"fabric_description"`
+	// discord invites in the repo metadata, like the description or homepage, which change without a push
+	MetadataInvites []string `json:"metadata_invites"`
+	Addon           *Addon   `json:"addon"`
+}
+
+// State remembers the result of previous scans so unchanged repos don't have to be parsed again
+type State struct {
+	Repos map[string]*RepoState
+	mu    sync.Mutex
+}
+
+func NewState() *State {
+	return &State{Repos: make(map[string]*RepoState)}
+}
+
+func (s *State) MarshalJSON() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return json.Marshal(stateFile{StateVersion, s.Repos})
+}
+
+func (s *State) UnmarshalJSON(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// states from before the version was stored are a bare map of repos, which leaves the version at 0
+	var file stateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+
+	if file.Version != StateVersion {
+		slog.Info("Scan state was written by another parser version, parsing every repo again", "version", file.Version, "current", StateVersion)
+		s.Repos = make(map[string]*RepoState)
+		return nil
+	}
+
+	s.Repos = file.Repos
+	if s.Repos == nil {
+		s.Repos = make(map[string]*RepoState)
+	}
+
+	return nil
+}
+
+type stateFile struct {
+	Version int                   `json:"version"`
+	Repos   map[string]*RepoState `json:"repos"`
+}
+
+// Get returns the state of the repo from the previous scan, nil if there is none
+func (s *State) Get(repo string) *RepoState {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.Repos[repo]
+}
+
+func (s *State) Set(repo string, state *RepoState) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Repos[repo] = state
+}
+
+func (s *State) Forget(repo string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.Repos, repo)
+}
+
+// unchanged reports whether neither the HEAD commit, the latest release nor the discord invites in the repo metadata
+// changed since the previous scan. Invites in README.md and fabric.mod.json can only change along with HEAD
+func (s *RepoState) unchanged(headSHA string, releaseId int64, metadataInvites []string) bool {
+	return s != nil && s.Addon != nil && s.HeadSHA == headSHA && s.ReleaseId == releaseId && slices.Equal(s.MetadataInvites, metadataInvites)
+}
+
+// refresh returns a copy of the cached addon with the fields that change
+// without a push (stars, downloads, ...) updated from the current scan
+func (s *RepoState) refresh(repo *repository, releases *releaseDetails) *Addon {
+	addon := *s.Addon
+	addon.entrypoint = s.Entrypoint
+
+	// the cached addon stays in the state, so it must not share feature slices with the new one
+	addon.Features.Modules = slices.Clone(addon.Features.Modules)
+	addon.Features.Commands = slices.Clone(addon.Features.Commands)
+	addon.Features.HudElements = slices.Clone(addon.Features.HudElements)
+
+	// a cleared repo description falls back to fabric.mod.json, like it does when parsing
+	addon.Description = repo.Description
+	if addon.Description == "" {
+		addon.Description = s.FabricDescription
+	}
+
+	addon.Repo.defaultBranch = repo.DefaultBranch
+	addon.Repo.Owner = repo.Owner.Login
+	addon.Repo.Name = repo.Name
+	addon.Repo.Archived = repo.Archived
+	addon.Repo.Fork = repo.Fork
+	addon.Repo.Forks = repo.Forks
+	addon.Repo.Stars = repo.Stars
+	addon.Repo.Downloads = releases.DownloadCount
+	addon.Repo.LastUpdate = repo.PushedAt
+	addon.Repo.Topics = repo.Topics
+
+	addon.Links.Github = sanitizeURL(repo.HtmlUrl)
+	addon.Links.Downloads = releases.Downloads
+	addon.Links.Prereleases = releases.Prereleases
+	addon.Links.LatestRelease = sanitizeURL(releases.LatestRelease)
+	addon.Links.Homepage = sanitizeURL(homepage(repo))
+
+	return &addon
+}
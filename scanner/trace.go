@@ -0,0 +1,57 @@
+package scanner
+
+import (
+	"fmt"
+)
+
+type TraceEvent struct {
+	Stage   string `json:"stage"`
+	Message string `json:"message"`
+}
+
+// Trace records every request made while parsing a repo and why each field got its value.
+// A nil trace records nothing, so parsing functions can always call it. It is not safe for concurrent use
+type Trace struct {
+	Events []TraceEvent
+}
+
+func NewTrace() *Trace {
+	return &Trace{}
+}
+
+func (t *Trace) add(stage string, format string, a ...any) {
+	if t == nil {
+		return
+	}
+
+	t.Events = append(t.Events, TraceEvent{stage, fmt.Sprintf(format, a...)})
+}
+
+// get makes a GET request and records the url and its result
+func (t *Trace) get(url string) ([]byte, error) {
+	bytes, status, err := makeGetRequest(url)
+	if t == nil {
+		return bytes, err
+	}
+
+	switch {
+	case err != nil && status == 0:
+		t.add("fetch", "GET %s -> %v", url, err)
+	case err != nil:
+		t.add("fetch", "GET %s -> %d: %v", url, status, err)
+	default:
+		t.add("fetch", "GET %s -> %d (%d bytes)", url, status, len(bytes))
+	}
+
+	return bytes, err
+}
+
+// truncates long matches so the trace stays readable
+func shorten(text string) string {
+	const maxLength = 120
+	if len(text) <= maxLength {
+		return text
+	}
+
+	return text[:maxLength] + "..."
+}
@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"regexp"
+	"strings"
 )
 
 type Config struct {
@@ -26,8 +27,26 @@ type Config struct {
 		FeatureCount      int `json:"feature_count"`
 		SupportedVersions int `json:"supported_versions"`
 	} `json:"suspicion_triggers"`
-	AllowedImageHosts []string `json:"allowed_image_hosts"`
-	DiscordWebhook    bool     `json:"discord_webhook"`
+	AllowedImageHosts  []string `json:"allowed_image_hosts"`
+	DiscordWebhook     bool     `json:"discord_webhook"`
+	StaleRetentionDays int      `json:"stale_retention_days"`
+	History            struct {
+		Points        int `json:"points"`
+		RetentionDays int `json:"retention_days"`
+	} `json:"history"`
+	Output struct {
+		Sort   string `json:"sort"`
+		Pretty bool   `json:"pretty"`
+	} `json:"output"`
+	Feeds struct {
+		BaseURL    string `json:"base_url"`
+		MaxEntries int    `json:"max_entries"`
+	} `json:"feeds"`
+	TagInference struct {
+		// keywords per tag, replacing the built-in ones of that tag
+		Keywords      map[string][]string `json:"keywords"`
+		MinConfidence float64             `json:"min_confidence"`
+	} `json:"tag_inference"`
 }
 
 type Tag int
@@ -78,6 +97,17 @@ func (t Tag) String() string {
 	}
 }
 
+// ParseTag returns the tag with the given name, ignoring case
+func ParseTag(name string) (Tag, bool) {
+	tag, ok := validTags[strings.ToLower(name)]
+	return tag, ok
+}
+
+// Tags returns every tag
+func Tags() []Tag {
+	return []Tag{PvP, Utility, Theme, Render, Movement, Building, World, Misc, QoL, Exploit, Fun, Automation}
+}
+
 var validTags = map[string]Tag{
 	"pvp":        PvP,
 	"utility":    Utility,
@@ -104,6 +134,35 @@ type Addon struct {
 	Repo        Repo   `json:"repo"`
 	Links       Links  `json:"links"`
 	Custom      Custom `json:"custom"`
+	// time of the last successful scan of the repo, kept while the entry is carried forward as stale
+	LastScanned string `json:"last_scanned"`
+	Stale       bool   `json:"stale,omitempty"`
+	Score       *Score `json:"score,omitempty"`
+	// tags guessed from the features, categories and topics, separate from the tags in Custom
+	InferredTags []InferredTag `json:"inferred_tags,omitempty"`
+}
+
+type InferredTag struct {
+	Tag string `json:"tag"`
+	// 0-1, how much evidence there is for the tag
+	Confidence float64 `json:"confidence"`
+}
+
+type Score struct {
+	// recent star and download growth, weighted by how recent it is
+	Trending float64 `json:"trending"`
+	// 0-100 score based on verification, activity, releases and feature descriptions
+	Quality   float64        `json:"quality"`
+	Breakdown ScoreBreakdown `json:"breakdown"`
+}
+
+type ScoreBreakdown struct {
+	StarGrowth     float64 `json:"star_growth"`
+	DownloadGrowth float64 `json:"download_growth"`
+	Verified       float64 `json:"verified"`
+	RecentUpdate   float64 `json:"recent_update"`
+	Release        float64 `json:"release"`
+	Descriptions   float64 `json:"descriptions"`
 }
 
 type Custom struct {
@@ -121,6 +180,7 @@ type Features struct {
 	HudElements  []Feature `json:"hud_elements"`
 	Tabs         []string  `json:"tabs"`
 	Themes       []string  `json:"themes"`
+	Categories   []string  `json:"categories"`
 	FeatureCount int       `json:"feature_count"`
 }
 
@@ -132,20 +192,23 @@ type Feature struct {
 type Repo struct {
 	Id            string `json:"id"`
 	defaultBranch string
-	Owner         string `json:"owner"`
-	Name          string `json:"name"`
-	Archived      bool   `json:"archived"`
-	Fork          bool   `json:"fork"`
-	Forks         int    `json:"forks"`
-	Stars         int    `json:"stars"`
-	Downloads     int    `json:"downloads"`
-	LastUpdate    string `json:"last_update"`
-	CreationDate  string `json:"creation_date"`
+	Owner         string   `json:"owner"`
+	Name          string   `json:"name"`
+	Archived      bool     `json:"archived"`
+	Fork          bool     `json:"fork"`
+	Forks         int      `json:"forks"`
+	Stars         int      `json:"stars"`
+	Downloads     int      `json:"downloads"`
+	LastUpdate    string   `json:"last_update"`
+	CreationDate  string   `json:"creation_date"`
+	Topics        []string `json:"topics"`
 }
 
 type Links struct {
-	Github        string   `json:"github"`
-	Downloads     []string `json:"downloads"`
+	Github    string   `json:"github"`
+	Downloads []string `json:"downloads"`
+	// the downloads that are from a prerelease
+	Prereleases   []string `json:"prereleases"`
 	LatestRelease string   `json:"latest_release"`
 	Discord       string   `json:"discord"`
 	Homepage      string   `json:"homepage"`
@@ -159,18 +222,19 @@ var inviteRegex = regexp.MustCompile(`((?:https?:\/\/)?(?:www\.)?(?:discord\.(?:
 var mcVersionRegex = regexp.MustCompile(`^(?:1\.\d+(?:\.\d+)?|\d{2}\.\d+(?:\.\d+)?)$`)
 
 type repository struct {
-	FullName      string `json:"full_name"`
-	Name          string `json:"name"`
-	Description   string `json:"description"`
-	Stars         int    `json:"stargazers_count"`
-	DefaultBranch string `json:"default_branch"`
-	HtmlUrl       string `json:"html_url"`
-	PushedAt      string `json:"pushed_at"`
-	CreatedAt     string `json:"created_at"`
-	Fork          bool   `json:"fork"`
-	Forks         int    `json:"forks_count"`
-	Archived      bool   `json:"archived"`
-	Homepage      string `json:"homepage"`
+	FullName      string   `json:"full_name"`
+	Name          string   `json:"name"`
+	Description   string   `json:"description"`
+	Stars         int      `json:"stargazers_count"`
+	DefaultBranch string   `json:"default_branch"`
+	HtmlUrl       string   `json:"html_url"`
+	PushedAt      string   `json:"pushed_at"`
+	CreatedAt     string   `json:"created_at"`
+	Fork          bool     `json:"fork"`
+	Forks         int      `json:"forks_count"`
+	Archived      bool     `json:"archived"`
+	Homepage      string   `json:"homepage"`
+	Topics        []string `json:"topics"`
 	Owner         struct {
 		Login string `json:"login"`
 	} `json:"owner"`
@@ -188,11 +252,21 @@ type fabric struct {
 }
 
 type release struct {
-	Draft      bool `json:"draft"`
-	Prerelease bool `json:"prerelease"`
+	Id         int64 `json:"id"`
+	Draft      bool  `json:"draft"`
+	Prerelease bool  `json:"prerelease"`
 	Assets     []struct {
 		Name      string `json:"name"`
 		Url       string `json:"browser_download_url"`
 		Downloads int    `json:"download_count"`
 	} `json:"assets"`
 }
+
+type releaseDetails struct {
+	Downloads     []string
+	Prereleases   []string
+	LatestRelease string
+	DownloadCount int
+	// id of the newest published release, 0 if there is none
+	LatestId int64
+}
@@ -1,8 +1,10 @@
 package scanner
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"strconv"
@@ -50,8 +52,44 @@ func getRateLimitTracker(apiType string) *RateLimitTracker {
 	}
 }
 
+// RateLimits returns the last known search and core rate limits
+func RateLimits() (RateLimitTracker, RateLimitTracker) {
+	rateLimits.mu.Lock()
+	defer rateLimits.mu.Unlock()
+
+	return rateLimits.Search, rateLimits.Core
+}
+
 const RetryAttempts int = 25
 
+// transientError marks a failure that is expected to clear up on its own,
+// such as a timeout, a rate limit or a server side error
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transientError) Unwrap() error {
+	return e.err
+}
+
+func transient(err error) error {
+	return &transientError{err}
+}
+
+// ErrUnauthorized is returned when GitHub rejects the API key
+var ErrUnauthorized = errors.New("GitHub rejected the API key")
+
+// IsTransient reports whether err was caused by a temporary failure
+// that is worth retrying on the next scan
+func IsTransient(err error) bool {
+	var t *transientError
+	return errors.As(err, &t)
+}
+
 func MakeHeadRequest(url string) (int, error) {
 	resp, err := http.Head(url)
 	if err != nil {
@@ -63,35 +101,42 @@ func MakeHeadRequest(url string) (int, error) {
 }
 
 func MakeGetRequest(url string) ([]byte, error) {
+	bytes, _, err := makeGetRequest(url)
+	return bytes, err
+}
+
+// makeGetRequest makes a GET request and also returns the status code, 0 if no response was received
+func makeGetRequest(url string) ([]byte, int, error) {
 	// Detect API type from URL (for pre-request check)
 	apiType := detectAPIType(url)
 
 	// Check rate limit BEFORE request
 	rateLimits.mu.Lock()
 	tracker := getRateLimitTracker(apiType)
+	var waitTime time.Duration
 	if tracker.Remaining <= 1 && time.Now().Before(tracker.Reset) {
-		waitTime := time.Until(tracker.Reset)
-		if waitTime > 0 {
-			fmt.Printf("[%s] Rate limit reached. Waiting %v seconds...",
-				apiType, waitTime.Seconds())
-			time.Sleep(waitTime + 1*time.Second)
-			fmt.Printf(" -> ")
-		}
+		waitTime = time.Until(tracker.Reset)
 	}
 	rateLimits.mu.Unlock()
 
+	// sleeps without the lock, so progress reports can still read the rate limits
+	if waitTime > 0 {
+		slog.Warn("Rate limit reached, waiting for reset", "stage", "fetch", "source", apiType, "wait", waitTime.Round(time.Second))
+		sleep(fmt.Sprintf("%s rate limit", apiType), waitTime+1*time.Second)
+	}
+
 	// Build and execute request
 	req, err := BuildRequest(url)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
 		if os.IsTimeout(err) {
-			return nil, fmt.Errorf("Request timeout after 30s: %v", err)
+			return nil, 0, transient(fmt.Errorf("Request timeout after 30s: %v", err))
 		}
-		return nil, err
+		return nil, 0, transient(err)
 	}
 	defer resp.Body.Close()
 
@@ -120,15 +165,43 @@ func MakeGetRequest(url string) ([]byte, error) {
 	rateLimits.mu.Unlock()
 
 	if resp.StatusCode == 403 && tracker.Remaining == 0 {
-		return nil, fmt.Errorf("GitHub API rate limit exceeded for %s", resourceType)
+		return nil, resp.StatusCode, transient(fmt.Errorf("GitHub API rate limit exceeded for %s", resourceType))
+	}
+
+	// an invalid key is not the fault of the repo, so it is retried on the next scan
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, resp.StatusCode, transient(ErrUnauthorized)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return nil, resp.StatusCode, transient(fmt.Errorf("Request to %s failed: %s", url, resp.Status))
 	}
 
 	bytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, resp.StatusCode, transient(err)
 	}
 
-	return bytes, nil
+	// the secondary rate limit answers with a 403 while the primary one still has requests left
+	if resp.StatusCode == http.StatusForbidden && (resp.Header.Get("Retry-After") != "" || strings.Contains(strings.ToLower(string(bytes)), "secondary rate limit")) {
+		// later requests wait until GitHub allows them again
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			rateLimits.mu.Lock()
+			tracker.Remaining = 0
+			tracker.Reset = time.Now().Add(time.Duration(seconds) * time.Second)
+			rateLimits.mu.Unlock()
+		}
+
+		return nil, resp.StatusCode, transient(fmt.Errorf("GitHub API secondary rate limit exceeded for %s", resourceType))
+	}
+
+	return bytes, resp.StatusCode, nil
+}
+
+// CheckAuth makes sure GitHub accepts the API key before a scan is started
+func CheckAuth() error {
+	_, err := MakeGetRequest("https://api.github.com/rate_limit")
+	return err
 }
 
 func InitDefaultHeaders(token string) {
@@ -209,6 +282,11 @@ func CompareMinecraftVersions(versionA, versionB string) int {
 }
 
 func validTag(tag string) (string, bool) {
-	realTag, ok := validTags[strings.ToLower(tag)]
+	realTag, ok := ParseTag(tag)
 	return realTag.String(), ok
 }
+
+// IsMinecraftVersion reports whether version looks like a Minecraft release, such as 1.21.4 or 26.1
+func IsMinecraftVersion(version string) bool {
+	return mcVersionRegex.MatchString(version)
+}
@@ -0,0 +1,41 @@
+package scanner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSecondaryRateLimitIsTransient(t *testing.T) {
+	cases := []struct {
+		name       string
+		retryAfter string
+		body       string
+		transient  bool
+	}{
+		{"retry after", "0", `{"message":"Forbidden"}`, true},
+		{"message", "", `{"message":"You have exceeded a secondary rate limit. Please wait a few minutes before you try again."}`, true},
+		{"forbidden", "", `{"message":"Resource not accessible by integration"}`, false},
+	}
+
+	for _, c := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-RateLimit-Remaining", "4000")
+			if c.retryAfter != "" {
+				w.Header().Set("Retry-After", c.retryAfter)
+			}
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(c.body))
+		}))
+
+		_, status, err := makeGetRequest(server.URL)
+		server.Close()
+
+		if status != http.StatusForbidden {
+			t.Errorf("%s: status %d, expected 403", c.name, status)
+		}
+		if IsTransient(err) != c.transient {
+			t.Errorf("%s: transient %v, expected %v (error %v)", c.name, IsTransient(err), c.transient, err)
+		}
+	}
+}